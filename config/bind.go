@@ -0,0 +1,198 @@
+package config
+
+import (
+	errors2 "errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hbttundar/scg-config/errors"
+)
+
+const (
+	// bindTag is the struct tag used to map a field to a config key.
+	bindTag = "scg"
+	// defaultTag holds the value used when a field's key is missing from the config.
+	defaultTag = "default"
+)
+
+// Bind populates out, which must be a non-nil pointer, from the config subtree at prefix.
+// Struct fields are matched by their `scg:"name"` tag, or case-insensitively by field
+// name when untagged; `scg:"-"` skips a field. Keys missing from the config leave the
+// field untouched unless it declares a `default:"..."` tag, which is parsed with the
+// same converters (comma-separated for slices). A missing prefix binds a struct from
+// an empty subtree, so only its defaults are applied; other targets report
+// errors.ErrKeyNotFound. Binding stops at the first value that cannot be converted and
+// the returned error names its full dotted path.
+func (g *Getter) Bind(prefix string, out any) error {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("%w: %T", errors.ErrInvalidBindTarget, out)
+	}
+
+	var src any = g.config
+
+	if prefix != "" {
+		val, err := g.Lookup(prefix)
+
+		switch {
+		case err == nil:
+			src = val
+		case errors2.Is(err, errors.ErrKeyNotFound) && isStruct(target.Elem()):
+			// Like a missing nested struct, a missing subtree still gets its defaults
+			src = map[string]any{}
+		default:
+			return fmt.Errorf("%w: %s", err, prefix)
+		}
+	}
+
+	return bindValue(src, target.Elem(), prefix)
+}
+
+// bindValue writes val into dst, binding structs in place so that fields which are
+// missing from the config keep their current value.
+func bindValue(val any, dst reflect.Value, path string) error {
+	if isStruct(dst) {
+		return bindStruct(val, dst, path)
+	}
+
+	converted, err := convertTo(val, dst.Type(), path)
+	if err != nil {
+		return err
+	}
+
+	dst.Set(converted)
+
+	return nil
+}
+
+// bindStruct binds a map-shaped value onto the fields of dst.
+func bindStruct(val any, dst reflect.Value, path string) error {
+	src := reflect.ValueOf(val)
+	if src.Kind() != reflect.Map {
+		return convertError(path, errors.ErrNotMap)
+	}
+
+	dstType := dst.Type()
+
+	for idx := range dstType.NumField() {
+		field := dstType.Field(idx)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, skip := fieldKey(field)
+		if skip {
+			continue
+		}
+
+		// Untagged embedded structs share their parent's keys.
+		if name == "" {
+			if err := bindStruct(val, dst.Field(idx), path); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		fieldPath := joinPath(path, name)
+
+		fieldVal, found := mapValue(src, name)
+		if !found || fieldVal == nil {
+			if err := bindDefault(field, dst.Field(idx), fieldPath); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := bindValue(fieldVal, dst.Field(idx), fieldPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bindDefault applies a field's default tag, and the defaults of nested structs whose
+// key is missing entirely.
+func bindDefault(field reflect.StructField, dst reflect.Value, path string) error {
+	def, ok := field.Tag.Lookup(defaultTag)
+	if !ok {
+		if isStruct(dst) {
+			return bindStruct(map[string]any{}, dst, path)
+		}
+
+		return nil
+	}
+
+	var val any = def
+
+	if dst.Kind() == reflect.Slice && dst.Type() != reflect.TypeFor[[]byte]() {
+		parts := strings.Split(def, ",")
+		items := make([]any, len(parts))
+
+		for i, part := range parts {
+			items[i] = strings.TrimSpace(part)
+		}
+
+		val = items
+	}
+
+	return bindValue(val, dst, path)
+}
+
+// isStruct reports whether dst is a struct that is bound field by field.
+func isStruct(dst reflect.Value) bool {
+	return dst.Kind() == reflect.Struct && !isConvertible(dst.Type())
+}
+
+// isConvertible reports whether typ is handled by a converter rather than bound field by field.
+func isConvertible(typ reflect.Type) bool {
+	_, ok := keyTypesByType[typ]
+
+	return ok
+}
+
+// fieldKey returns the config key for a struct field and whether it should be skipped.
+// An empty key means the field is an untagged embedded struct.
+func fieldKey(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get(bindTag)
+	if tag == "-" {
+		return "", true
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name != "" {
+		return name, false
+	}
+
+	if field.Anonymous && field.Type.Kind() == reflect.Struct {
+		return "", false
+	}
+
+	return strings.ToLower(field.Name), false
+}
+
+// mapValue looks key up in any string-keyed map, falling back to a case-insensitive match.
+func mapValue(src reflect.Value, key string) (any, bool) {
+	iter := src.MapRange()
+
+	var (
+		fallback any
+		found    bool
+	)
+
+	for iter.Next() {
+		name := fmt.Sprint(iter.Key().Interface())
+		if name == key {
+			return iter.Value().Interface(), true
+		}
+
+		if !found && strings.EqualFold(name, key) {
+			fallback, found = iter.Value().Interface(), true
+		}
+	}
+
+	return fallback, found
+}
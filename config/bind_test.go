@@ -0,0 +1,121 @@
+package config_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/errors"
+)
+
+type replicaConfig struct {
+	Host string `scg:"host"`
+	Port int    `scg:"port"`
+}
+
+type poolConfig struct {
+	Size    uint8         `scg:"size"`
+	Timeout time.Duration `scg:"timeout"`
+}
+
+type databaseConfig struct {
+	ID       uuid.UUID         `scg:"id"`
+	URL      *url.URL          `scg:"url"`
+	MaxConns int               `scg:"max_conns"`
+	Pool     poolConfig        `scg:"pool"`
+	Replicas []replicaConfig   `scg:"replicas"`
+	Labels   map[string]string `scg:"labels"`
+	Primary  *replicaConfig    `scg:"primary"`
+	Ignored  string            `scg:"-"`
+	Name     string
+}
+
+func bindConfigMap() map[string]any {
+	return map[string]any{
+		"database": map[string]any{
+			"id":        "1336301d-4e85-4b76-a2f7-a2fc8ec10888",
+			"url":       "postgres://localhost:5432/app",
+			"max_conns": 20,
+			"pool":      map[string]any{"size": 5, "timeout": "30s"},
+			"replicas": []any{
+				map[string]any{"host": "r1", "port": 5433},
+				map[string]any{"host": "r2", "port": "5434"},
+			},
+			"labels":  map[string]any{"team": "core"},
+			"primary": map[string]any{"host": "p1", "port": 5432},
+			"ignored": "nope",
+			"name":    "main",
+		},
+	}
+}
+
+func TestGetter_Bind(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewGetter(bindConfigMap())
+
+	var dbCfg databaseConfig
+
+	dbCfg.Ignored = "keep"
+
+	require.NoError(t, conf.Bind("database", &dbCfg))
+
+	assert.Equal(t, "1336301d-4e85-4b76-a2f7-a2fc8ec10888", dbCfg.ID.String())
+	assert.Equal(t, "localhost:5432", dbCfg.URL.Host)
+	assert.Equal(t, 20, dbCfg.MaxConns)
+	assert.Equal(t, poolConfig{Size: 5, Timeout: 30 * time.Second}, dbCfg.Pool)
+	assert.Equal(t, []replicaConfig{{"r1", 5433}, {"r2", 5434}}, dbCfg.Replicas)
+	assert.Equal(t, map[string]string{"team": "core"}, dbCfg.Labels)
+	assert.Equal(t, &replicaConfig{"p1", 5432}, dbCfg.Primary)
+	assert.Equal(t, "keep", dbCfg.Ignored)
+	assert.Equal(t, "main", dbCfg.Name)
+}
+
+func TestGetter_BindWholeConfig(t *testing.T) {
+	t.Parallel()
+
+	var root struct {
+		Database struct {
+			MaxConns int `scg:"max_conns"`
+		} `scg:"database"`
+	}
+
+	require.NoError(t, config.NewGetter(bindConfigMap()).Bind("", &root))
+	assert.Equal(t, 20, root.Database.MaxConns)
+}
+
+func TestGetter_BindErrors(t *testing.T) {
+	t.Parallel()
+
+	data := bindConfigMap()
+	data["database"].(map[string]any)["replicas"] = []any{
+		map[string]any{"host": "r1", "port": "not-a-port"},
+	}
+	conf := config.NewGetter(data)
+
+	var dbCfg databaseConfig
+
+	err := conf.Bind("database", &dbCfg)
+	require.ErrorIs(t, err, errors.ErrWrongType)
+	require.ErrorIs(t, err, errors.ErrNotInt)
+	assert.Contains(t, err.Error(), "database.replicas.0.port")
+
+	var overflow struct {
+		Pool poolConfig `scg:"pool"`
+	}
+
+	data["database"].(map[string]any)["pool"] = map[string]any{"size": 300}
+	err = conf.Bind("database", &overflow)
+	require.ErrorIs(t, err, errors.ErrWrongType)
+	assert.Contains(t, err.Error(), "database.pool.size")
+
+	require.ErrorIs(t, conf.Bind("database", dbCfg), errors.ErrInvalidBindTarget)
+	var port int
+
+	require.ErrorIs(t, conf.Bind("missing", &port), errors.ErrKeyNotFound)
+}
@@ -2,9 +2,14 @@ package config
 
 import (
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/dotmap"
+	"github.com/hbttundar/scg-config/errors"
 	"github.com/hbttundar/scg-config/loader/env"
 	"github.com/hbttundar/scg-config/loader/file"
 	"github.com/hbttundar/scg-config/provider/viper"
@@ -13,14 +18,28 @@ import (
 
 // Config is the core config service, exposing only ValueAccessor API.
 type Config struct {
-	provider     contract.Provider
-	getter       *Getter
-	watcher      contract.Watcher
-	fileLoader   contract.FileLoader
-	envLoader    contract.EnvLoader
-	watchedFiles map[string]bool
-	done         chan struct{}
-	mu           sync.RWMutex
+	provider      contract.Provider
+	defaults      contract.Provider
+	flags         contract.Provider
+	flagOrigins   originMap
+	order         []contract.Layer
+	validators    []contract.Validator
+	schemas       atomic.Pointer[[]contract.Validator]
+	listeners     []ChangeListener
+	errorHandlers []func(error)
+	lastReloadErr error
+	lastGoodAt    time.Time
+	snapshot      atomic.Pointer[Snapshot]
+	known         atomic.Pointer[knownLayers]
+	stagedFiles   atomic.Pointer[contract.Provider]
+	watcher       contract.Watcher
+	fileLoader    contract.FileLoader
+	envLoader     contract.EnvLoader
+	watchedFiles  map[string]bool
+	profileEnv    string
+	watchOnce     sync.Once
+	done          chan struct{}
+	mu            sync.RWMutex
 }
 
 // Option is a functional option for configuring the Config instance.
@@ -33,14 +52,28 @@ func WithEnvLoader(el contract.EnvLoader) Option   { return func(c *Config) { c.
 
 func New(opts ...Option) *Config {
 	cfg := &Config{
-		provider:     nil,
-		getter:       nil,
-		watcher:      nil,
-		fileLoader:   nil,
-		envLoader:    nil,
-		watchedFiles: make(map[string]bool),
-		done:         make(chan struct{}),
-		mu:           sync.RWMutex{},
+		provider:      nil,
+		defaults:      viper.NewConfigProvider(),
+		flags:         viper.NewConfigProvider(),
+		flagOrigins:   make(originMap),
+		order:         slices.Clone(defaultLayerOrder),
+		validators:    nil,
+		schemas:       atomic.Pointer[[]contract.Validator]{},
+		listeners:     nil,
+		errorHandlers: nil,
+		lastReloadErr: nil,
+		lastGoodAt:    time.Time{},
+		snapshot:      atomic.Pointer[Snapshot]{},
+		known:         atomic.Pointer[knownLayers]{},
+		stagedFiles:   atomic.Pointer[contract.Provider]{},
+		watcher:       nil,
+		fileLoader:    nil,
+		envLoader:     nil,
+		watchedFiles:  make(map[string]bool),
+		profileEnv:    DefaultProfileEnv,
+		watchOnce:     sync.Once{},
+		done:          make(chan struct{}),
+		mu:            sync.RWMutex{},
 	}
 	for _, opt := range opts {
 		opt(cfg)
@@ -50,40 +83,98 @@ func New(opts ...Option) *Config {
 		cfg.provider = viper.NewConfigProvider()
 	}
 
+	// Each loader gets its own provider so that files and env stay separate layers.
 	if cfg.fileLoader == nil {
-		cfg.fileLoader = file.NewFileLoader(cfg.provider)
+		cfg.fileLoader = file.NewFileLoader(viper.NewConfigProvider())
 	}
 
 	if cfg.envLoader == nil {
-		cfg.envLoader = env.NewEnvLoader(cfg.provider)
+		cfg.envLoader = env.NewEnvLoader(viper.NewConfigProvider())
 	}
 
 	if cfg.watcher == nil {
 		cfg.watcher = watcher.NewWatcher(nil)
 	}
-	// Snapshot config map for the getter
-	cfg.getter = NewGetter(cfg.provider.AllSettings())
+	// Publish the first snapshot of the merged layers
+	cfg.publish(cfg.settings())
 
 	// Set the config reference in the watcher after the config is fully constructed
 	if w, ok := cfg.watcher.(*watcher.Watcher); ok {
 		w.SetConfig(cfg)
 	}
 
+	// Let the env loader match variable names and convert values to the keys the
+	// config knows
+	if l, ok := cfg.envLoader.(knownKeysSetter); ok {
+		l.SetKnownKeys(cfg.knownKeys)
+	}
+
+	if l, ok := cfg.envLoader.(keyTypesSetter); ok {
+		l.SetKeyTypes(cfg.keyTypes)
+	}
+
 	return cfg
 }
 
 // --- ValueAccessor API only ---.
 func (c *Config) Get(key string, typ contract.KeyType) (any, error) {
-	return c.getter.Get(key, typ)
+	return c.Snapshot().Get(key, typ)
 }
 
 func (c *Config) Has(key string) bool {
-	return c.getter.HasKey(key)
+	return c.Snapshot().Has(key)
+}
+
+// Lookup returns the raw, unconverted value stored at key.
+func (c *Config) Lookup(key string) (any, error) {
+	return c.Snapshot().Lookup(key)
+}
+
+// Bind populates out from the config subtree at prefix (see Getter.Bind).
+func (c *Config) Bind(prefix string, out any) error {
+	return c.Snapshot().Bind(prefix, out)
+}
+
+// Snapshot returns the current immutable view of the configuration. Reloads publish
+// a new snapshot and never modify one that has been handed out.
+func (c *Config) Snapshot() *Snapshot {
+	return c.snapshot.Load()
+}
+
+// Diff returns what changed from this config to other, e.g. staging to production
+// (see dotmap.Diff).
+func (c *Config) Diff(other *Config) []dotmap.Change {
+	return c.Snapshot().Diff(other.Snapshot())
 }
 
+// publish freezes settings into a new snapshot and makes it visible to readers
+// atomically. Assumes c.mu is held for writing (or that c is not yet shared).
+// The returned change is passed to notify once the lock has been released.
+func (c *Config) publish(settings map[string]any) change {
+	previous := c.snapshot.Load()
+
+	var version uint64
+	if previous != nil {
+		version = previous.version + 1
+	}
+
+	current := newSnapshot(settings, version)
+	c.snapshot.Store(current)
+	c.publishKnown()
+
+	return change{previous: previous, current: current}
+}
+
+// ReadInConfig re-reads every source loaded through the file and env loaders, and the
+// config file the provider passed to WithProvider read, without publishing a new
+// snapshot; the next Reload or Set does. Like Reload it is
+// transactional: when a source fails to read or the result fails validation, the
+// loaders keep their previous values.
 func (c *Config) ReadInConfig() error {
-	err := c.provider.ReadInConfig()
-	if err != nil {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.readSourcesLocked(); err != nil {
 		return fmt.Errorf("error reading config: %w", err)
 	}
 
@@ -115,17 +206,69 @@ func (c *Config) WatchedFiles() []string {
 	return files
 }
 
+// StartWatching reloads the config whenever filePath changes. Every change goes
+// through Reload, so the snapshot is rebuilt, validated and announced to OnChange
+// listeners exactly as for a manual reload. Changes to several watched files that the
+// watcher dispatches together cause a single reload. A failed reload, or a failure of
+// the watcher itself, is passed to the OnError handlers.
 func (c *Config) StartWatching(filePath string) error {
-	err := c.watcher.AddFile(filePath, func() {
-		_ = c.provider.ReadInConfig()
-	})
-	if err != nil {
+	if err := c.watcher.AddFile(filePath, nil); err != nil {
 		return fmt.Errorf("error starting watcher for file %s: %w", filePath, err)
 	}
 
+	c.reloadOnChange()
+
 	return nil
 }
 
+// WatchDirectory reloads the config whenever a config file in dir is added, removed or
+// changed, like StartWatching does for a single file. The reload re-reads the
+// directory, so the files are merged in the same order as by
+// FileLoader().LoadFromDirectory, which dir is expected to have been loaded with.
+// The files watched are the ones the file loader would load: with the built-in loader
+// and watchers, subdirectories are watched when it loads recursively, and files its
+// WithInclude, WithExclude or WithSymlinks options leave out are ignored. The watcher
+// must implement contract.DirectoryWatcher, as the built-in watchers do.
+func (c *Config) WatchDirectory(dir string) error {
+	if err := c.watchDirectory(dir); err != nil {
+		return fmt.Errorf("error starting watcher for directory %s: %w", dir, err)
+	}
+
+	c.reloadOnChange()
+
+	return nil
+}
+
+// watchDirectory adds dir to the watcher, listed by the file loader when both support
+// it and as the watcher lists directories otherwise.
+func (c *Config) watchDirectory(dir string) error {
+	lister, canList := c.fileLoader.(contract.DirectoryLister)
+	if listingWatcher, ok := c.watcher.(contract.ListingWatcher); ok && canList {
+		return listingWatcher.AddListing(dir, func() ([]string, []string, error) {
+			return lister.ListDirectory(dir)
+		}, nil)
+	}
+
+	dirWatcher, ok := c.watcher.(contract.DirectoryWatcher)
+	if !ok {
+		return errors.ErrWatchDirectoryNotSupported
+	}
+
+	return dirWatcher.AddDirectory(dir, nil)
+}
+
+// reloadOnChange makes the watcher reload the config once per batch of changes.
+func (c *Config) reloadOnChange() {
+	c.watchOnce.Do(func() {
+		c.watcher.OnError(c.reportError)
+		c.watcher.Watch(func() {
+			if err := c.Reload(); err != nil {
+				c.reportError(err)
+			}
+		})
+	})
+}
+
 func (c *Config) Close() error {
 	close(c.done)
 
@@ -167,17 +310,5 @@ func (c *Config) Watcher() contract.Watcher {
 	return c.watcher
 }
 
-// Reload reloads the configuration from the provider and updates the getter.
-func (c *Config) Reload() error {
-	err := c.provider.ReadInConfig()
-	if err != nil {
-		return fmt.Errorf("error reloading config: %w", err)
-	}
-
-	c.getter = NewGetter(c.provider.AllSettings())
-
-	return nil
-}
-
 // --- Interface assertion: only ValueAccessor, not ValueReader! ---.
 var _ contract.Config = (*Config)(nil)
@@ -0,0 +1,16 @@
+package config
+
+import (
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/decoder"
+)
+
+// RegisterDecoder teaches the file loader and the watcher a new config format, or
+// replaces the decoder of a built-in one, for every extension d lists. Registration is
+// process-wide, so it is usually done once from an init function.
+//
+//	config.RegisterDecoder(json5Decoder{}) // Extensions() returns []string{".json5"}
+//	cfg.FileLoader().LoadFromFile("config/app.json5")
+func RegisterDecoder(d contract.Decoder) {
+	decoder.Register(d)
+}
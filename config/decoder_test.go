@@ -0,0 +1,95 @@
+package config_test
+
+import (
+	"bufio"
+	"bytes"
+	errors2 "errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/errors"
+)
+
+var errBadLine = errors2.New("line is not key = value")
+
+// keyValueDecoder decodes a made-up in-house format of "section.key = value" lines.
+type keyValueDecoder struct{}
+
+func (keyValueDecoder) Decode(data []byte) (map[string]any, error) {
+	settings := make(map[string]any)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, errBadLine
+		}
+
+		section, name, _ := strings.Cut(strings.TrimSpace(key), ".")
+		nested, _ := settings[section].(map[string]any)
+		if nested == nil {
+			nested = make(map[string]any)
+			settings[section] = nested
+		}
+
+		nested[name] = strings.TrimSpace(value)
+	}
+
+	return settings, nil
+}
+
+func (keyValueDecoder) Extensions() []string { return []string{"kv", ".LEGACY"} }
+
+func TestRegisterDecoder(t *testing.T) {
+	t.Parallel()
+
+	config.RegisterDecoder(keyValueDecoder{})
+
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "app:\n  name: yaml\n  port: 8080\n")
+	writeFile(t, dir, "b.kv", "# in-house format\napp.name = kv\nDB.Host = db\n")
+	writeFile(t, dir, "c.legacy", "cache.ttl = 60\n")
+
+	cfg := config.New()
+	require.NoError(t, cfg.FileLoader().LoadFromDirectory(dir))
+	require.NoError(t, cfg.Reload())
+
+	assert.Equal(t, "kv", config.MustGet[string](cfg, "app.name"))
+	assert.Equal(t, 8080, config.MustGet[int](cfg, "app.port"))
+	assert.Equal(t, "db", config.MustGet[string](cfg, "db.host"))
+	assert.Equal(t, "60", config.MustGet[string](cfg, "cache.ttl"))
+
+	explained, err := cfg.Explain("db.host")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "b.kv"), explained.Origin.File)
+
+	// A custom format can also be the first, or only, file loaded.
+	single := config.New()
+	require.NoError(t, single.FileLoader().LoadFromFile(filepath.Join(dir, "b.kv")))
+	require.NoError(t, single.Reload())
+	assert.Equal(t, "kv", config.MustGet[string](single, "app.name"))
+}
+
+func TestRegisterDecoder_DecodeError(t *testing.T) {
+	t.Parallel()
+
+	config.RegisterDecoder(keyValueDecoder{})
+
+	dir := t.TempDir()
+	writeFile(t, dir, "broken.kv", "not a key value line\n")
+
+	cfg := config.New()
+	err := cfg.FileLoader().LoadFromFile(filepath.Join(dir, "broken.kv"))
+	require.ErrorIs(t, err, errors.ErrParseConfigFileFailed)
+	require.ErrorIs(t, err, errBadLine)
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"github.com/hbttundar/scg-config/contract"
+)
+
+// WithDefaults registers default values that sit below every loaded source.
+// Keys may use dot notation or nested maps.
+func WithDefaults(defaults map[string]any) Option {
+	return func(c *Config) {
+		for key, value := range defaults {
+			c.defaults.Set(key, value)
+		}
+	}
+}
+
+// SetDefault registers a default value for key. Files, env and overrides still win.
+func (c *Config) SetDefault(key string, value any) {
+	c.mu.Lock()
+	c.defaults.Set(key, value)
+	ch := c.publish(c.settings())
+	c.mu.Unlock()
+
+	c.notify(ch)
+}
+
+// Source reports which layer the current value of key comes from.
+func (c *Config) Source(key string) (contract.Layer, error) {
+	explanation, err := c.Explain(key)
+	if err != nil {
+		return "", err
+	}
+
+	return explanation.Origin.Layer, nil
+}
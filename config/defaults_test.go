@@ -0,0 +1,100 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/provider/viper"
+)
+
+func TestConfig_Defaults(t *testing.T) {
+	t.Parallel()
+
+	prov := viper.NewConfigProvider()
+	prov.Set("server.port", 9090)
+
+	cfg := config.New(
+		config.WithProvider(prov),
+		config.WithDefaults(map[string]any{
+			"server.port": 8080,
+			"server":      map[string]any{"host": "localhost"},
+		}),
+	)
+	cfg.SetDefault("server.timeout", "30s")
+
+	port, err := cfg.Get("server.port", contract.Int)
+	require.NoError(t, err)
+	assert.Equal(t, 9090, port, "provider value must win over the default")
+
+	host, err := cfg.Get("server.host", contract.String)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", host)
+
+	assert.True(t, cfg.Has("server.timeout"))
+	assert.Equal(t, 30*time.Second, config.MustGet[time.Duration](cfg, "server.timeout"))
+
+	source, err := cfg.Source("server.port")
+	require.NoError(t, err)
+	assert.Equal(t, contract.LayerOverrides, source)
+
+	source, err = cfg.Source("server.host")
+	require.NoError(t, err)
+	assert.Equal(t, contract.LayerDefaults, source)
+
+	_, err = cfg.Source("server.missing")
+	require.ErrorIs(t, err, errors.ErrKeyNotFound)
+}
+
+func TestGetter_BindDefaultTags(t *testing.T) {
+	t.Parallel()
+
+	type limits struct {
+		Burst int `default:"10" scg:"burst"`
+	}
+
+	var server struct {
+		Port    int           `default:"8080" scg:"port"`
+		Timeout time.Duration `default:"30s"  scg:"timeout"`
+		Hosts   []string      `default:"a, b" scg:"hosts"`
+		Limits  limits        `scg:"limits"`
+		Name    string        `scg:"name"`
+	}
+
+	conf := config.NewGetter(map[string]any{"server": map[string]any{"port": 9090}})
+	require.NoError(t, conf.Bind("server", &server))
+
+	assert.Equal(t, 9090, server.Port)
+	assert.Equal(t, 30*time.Second, server.Timeout)
+	assert.Equal(t, []string{"a", "b"}, server.Hosts)
+	assert.Equal(t, 10, server.Limits.Burst)
+	assert.Empty(t, server.Name)
+
+	var invalid struct {
+		Timeout time.Duration `default:"soon" scg:"timeout"`
+	}
+
+	err := conf.Bind("server", &invalid)
+	require.ErrorIs(t, err, errors.ErrNotDuration)
+	assert.Contains(t, err.Error(), "server.timeout")
+}
+
+func TestGetter_BindDefaultTagsMissingPrefix(t *testing.T) {
+	t.Parallel()
+
+	var database struct {
+		Host    string        `scg:"host"`
+		Timeout time.Duration `default:"30s" scg:"timeout"`
+	}
+
+	conf := config.NewGetter(map[string]any{"server": map[string]any{"port": 9090}})
+	require.NoError(t, conf.Bind("database", &database))
+
+	assert.Equal(t, 30*time.Second, database.Timeout)
+	assert.Empty(t, database.Host)
+}
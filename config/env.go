@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+)
+
+// BindEnv feeds key from the first of envNames that is set, e.g.
+// BindEnv("database.url", "DATABASE_URL", "PG_URL"), and publishes the result (see
+// env.Loader.Bind). The variables are read again on every reload. Like Reload, the
+// binding is only applied if the merged result passes validation.
+func (c *Config) BindEnv(key string, envNames ...string) error {
+	binder, ok := c.envLoader.(contract.EnvBinder)
+	if !ok {
+		return errors.ErrEnvBindingNotSupported
+	}
+
+	c.mu.Lock()
+	ch, err := c.applyStaged(contract.LayerEnv, func() (contract.Stage, error) {
+		return binder.StageBind(key, envNames...)
+	})
+	c.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("error binding %s to %v: %w", key, envNames, err)
+	}
+
+	c.notify(ch)
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/loader/env"
+	"github.com/hbttundar/scg-config/provider/viper"
+	"github.com/hbttundar/scg-config/schema"
+)
+
+func TestConfig_BindEnv(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://db")
+
+	cfg := config.New()
+	require.NoError(t, cfg.BindEnv("database.url", "DATABASE_URL", "PG_URL"))
+
+	// Published right away, without a Reload.
+	assert.Equal(t, "postgres://db", config.MustGet[string](cfg, "database.url"))
+
+	explanation, err := cfg.Explain("database.url")
+	require.NoError(t, err)
+	assert.Equal(t, contract.LayerEnv, explanation.Origin.Layer)
+	assert.Equal(t, "DATABASE_URL", explanation.Origin.EnvVar)
+
+	t.Setenv("DATABASE_URL", "postgres://other")
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, "postgres://other", config.MustGet[string](cfg, "database.url"))
+}
+
+func TestConfig_BindEnvRejectedByValidation(t *testing.T) {
+	t.Parallel()
+
+	portSchema := schema.New()
+	portSchema.Field("server.port").Max(1024)
+
+	loader := env.NewEnvLoader(viper.NewConfigProvider(), env.WithEnvMap(map[string]string{"PORT": "70000"}))
+	cfg := config.New(config.WithEnvLoader(loader), config.WithSchema(portSchema), config.WithDefaults(map[string]any{
+		"server": map[string]any{"port": 80},
+	}))
+
+	require.ErrorIs(t, cfg.BindEnv("server.port", "PORT"), errors.ErrValidationFailed)
+	assert.Equal(t, 80, config.MustGet[int](cfg, "server.port"))
+	assert.False(t, loader.GetProvider().IsSet("server.port"))
+
+	// The rejected binding is not remembered either.
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, 80, config.MustGet[int](cfg, "server.port"))
+}
+
+func TestConfig_BindEnvSharedProviderRejectedByValidation(t *testing.T) {
+	t.Parallel()
+
+	portSchema := schema.New()
+	portSchema.Field("server.port").Max(1024)
+
+	// The env loader shares the overrides provider, so the binding cannot be swapped in.
+	provider := viper.NewConfigProvider()
+	loader := env.NewEnvLoader(provider, env.WithEnvMap(map[string]string{"PORT": "70000", "HOST": "db"}))
+	cfg := config.New(config.WithProvider(provider), config.WithEnvLoader(loader), config.WithSchema(portSchema),
+		config.WithDefaults(map[string]any{"server": map[string]any{"port": 80}}))
+
+	require.ErrorIs(t, cfg.BindEnv("server.port", "PORT"), errors.ErrValidationFailed)
+	assert.False(t, provider.IsSet("server.port"))
+
+	cfg.Set("x", 1)
+	assert.Equal(t, 80, config.MustGet[int](cfg, "server.port"))
+
+	require.NoError(t, cfg.BindEnv("server.host", "HOST"))
+	assert.Equal(t, "db", config.MustGet[string](cfg, "server.host"))
+	assert.Equal(t, 1, config.MustGet[int](cfg, "x"), "values set with Set survive a binding on the shared provider")
+
+	// The rejected binding is not remembered either.
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, 80, config.MustGet[int](cfg, "server.port"))
+}
+
+func TestConfig_KeyMatchingIgnoresEnvKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		envFirst bool
+	}{
+		{name: "files first", envFirst: false},
+		{name: "env first", envFirst: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			writeFile(t, dir, "app.yaml", "app:\n  name: demo\n")
+
+			loader := env.NewEnvLoader(viper.NewConfigProvider(), env.WithKeyMatching(),
+				env.WithEnvMap(map[string]string{"APP_CACHE_MAX_SIZE": "99"}))
+			cfg := config.New(config.WithEnvLoader(loader))
+
+			if tt.envFirst {
+				require.NoError(t, cfg.EnvLoader().LoadFromEnv("APP"))
+				require.NoError(t, cfg.FileLoader().LoadFromDirectory(dir))
+			} else {
+				require.NoError(t, cfg.FileLoader().LoadFromDirectory(dir))
+				require.NoError(t, cfg.EnvLoader().LoadFromEnv("APP"))
+			}
+
+			require.NoError(t, cfg.Reload())
+
+			// Until a file knows cache.max_size the variable maps to cache.max.size, which
+			// must not keep matching once the file adds the real key.
+			writeFile(t, dir, "cache.yaml", "cache:\n  max_size: 10\n")
+
+			for range 3 {
+				require.NoError(t, cfg.Reload())
+				assert.Equal(t, 99, config.MustGet[int](cfg, "cache.max_size"))
+			}
+		})
+	}
+}
+
+// plainEnvLoader is an EnvLoader without binding support.
+type plainEnvLoader struct{ provider contract.Provider }
+
+func (l plainEnvLoader) LoadFromEnv(string) error { return nil }
+
+//nolint:ireturn // returning an interface is required by the contract API
+func (l plainEnvLoader) GetProvider() contract.Provider { return l.provider }
+
+func TestConfig_BindEnvUnsupported(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New(config.WithEnvLoader(plainEnvLoader{provider: viper.NewConfigProvider()}))
+
+	require.ErrorIs(t, cfg.BindEnv("server.port", "PORT"), errors.ErrEnvBindingNotSupported)
+}
+
+func TestConfig_ReloadWithFileSecrets(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "name: v1\n")
+	secret := filepath.Join(dir, "db_password")
+	require.NoError(t, os.WriteFile(secret, []byte("s3cret\n"), 0o600))
+
+	vars := map[string]string{"APP_DB_PASSWORD_FILE": secret, "APP_LOG_FILE": "/nonexistent/app.log"}
+	loader := env.NewEnvLoader(viper.NewConfigProvider(), env.WithFileSecrets(0, "db.password"), env.WithEnvMap(vars))
+
+	cfg := config.New(config.WithEnvLoader(loader))
+	require.NoError(t, cfg.FileLoader().LoadFromFile(path))
+	require.NoError(t, loader.LoadFromEnv("APP"))
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, "s3cret", config.MustGet[string](cfg, "db.password"))
+	assert.Equal(t, "/nonexistent/app.log", config.MustGet[string](cfg, "log.file"))
+
+	// An unrelated _FILE variable never blocks reloads of other sources.
+	writeFile(t, dir, "app.yaml", "name: v2\n")
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, "v2", config.MustGet[string](cfg, "name"))
+
+	// A declared secret that cannot be read rejects the reload, like a broken file.
+	require.NoError(t, os.Remove(secret))
+	writeFile(t, dir, "app.yaml", "name: v3\n")
+	require.ErrorIs(t, cfg.Reload(), errors.ErrSecretFileFailed)
+	assert.Equal(t, "v2", config.MustGet[string](cfg, "name"))
+	assert.Equal(t, "s3cret", config.MustGet[string](cfg, "db.password"))
+}
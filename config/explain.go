@@ -0,0 +1,63 @@
+package config
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/dotmap"
+	"github.com/hbttundar/scg-config/errors"
+)
+
+// LayerValue is the value one layer holds for a key, and where it came from.
+type LayerValue struct {
+	Value  any
+	Origin contract.Origin
+}
+
+// Explanation describes how the value of a key was resolved across layers.
+type Explanation struct {
+	Key string
+	// Value is the effective value, as returned by Lookup.
+	Value any
+	// Origin is where the winning value came from.
+	Origin contract.Origin
+	// Shadowed lists the values of lower layers, highest precedence first.
+	Shadowed []LayerValue
+}
+
+// Explain reports the winning value of key, the layer and file, line, env var or flag
+// it came from, and the values it shadows in lower layers.
+func (c *Config) Explain(key string) (Explanation, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, err := c.Snapshot().Lookup(key)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	normalized := strings.ToLower(key)
+
+	var found []LayerValue
+
+	for _, l := range slices.Backward(c.layers()) {
+		layerValue := dotmap.Resolve(l.provider.AllSettings(), key)
+		if layerValue == nil {
+			continue
+		}
+
+		found = append(found, LayerValue{Value: layerValue, Origin: l.origin(normalized)})
+	}
+
+	if len(found) == 0 {
+		return Explanation{}, errors.ErrKeyNotFound
+	}
+
+	return Explanation{
+		Key:      key,
+		Value:    value,
+		Origin:   found[0].Origin,
+		Shadowed: found[1:],
+	}, nil
+}
@@ -0,0 +1,92 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestConfig_LayerPrecedence(t *testing.T) {
+	t.Setenv("EXPLAIN_SERVER_PORT", "7070")
+	t.Setenv("EXPLAIN_SERVER_HOST", "env-host")
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "server:\n  port: 8080\n  host: file-host\n  name: file-name\n")
+
+	cfg := config.New(config.WithDefaults(map[string]any{"server.port": 1, "server.debug": true}))
+	require.NoError(t, cfg.FileLoader().LoadFromFile(path))
+	require.NoError(t, cfg.EnvLoader().LoadFromEnv("EXPLAIN"))
+	require.NoError(t, cfg.Reload())
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Int("server.port", 0, "port")
+	require.NoError(t, flags.Parse([]string{"-server.port=6060"}))
+	cfg.LoadFlags(flags)
+
+	assert.Equal(t, 6060, config.MustGet[int](cfg, "server.port"))
+	assert.Equal(t, "env-host", config.MustGet[string](cfg, "server.host"))
+	assert.Equal(t, "file-name", config.MustGet[string](cfg, "server.name"))
+	assert.True(t, config.MustGet[bool](cfg, "server.debug"))
+
+	cfg.Set("server.port", 5050)
+	assert.Equal(t, 5050, config.MustGet[int](cfg, "server.port"))
+
+	explanation, err := cfg.Explain("server.port")
+	require.NoError(t, err)
+	assert.Equal(t, 5050, explanation.Value)
+	assert.Equal(t, contract.LayerOverrides, explanation.Origin.Layer)
+	require.Len(t, explanation.Shadowed, 4)
+	assert.Equal(t, contract.Origin{Layer: contract.LayerFlags, Flag: "server.port"}, explanation.Shadowed[0].Origin)
+	assert.Equal(t, contract.Origin{Layer: contract.LayerEnv, EnvVar: "EXPLAIN_SERVER_PORT"}, explanation.Shadowed[1].Origin)
+	assert.Equal(t, "7070", explanation.Shadowed[1].Value)
+	assert.Equal(t, contract.Origin{Layer: contract.LayerFiles, File: path, Line: 2}, explanation.Shadowed[2].Origin)
+	assert.Equal(t, 8080, explanation.Shadowed[2].Value)
+	assert.Equal(t, contract.LayerDefaults, explanation.Shadowed[3].Origin.Layer)
+
+	explanation, err = cfg.Explain("server.name")
+	require.NoError(t, err)
+	assert.Equal(t, contract.Origin{Layer: contract.LayerFiles, File: path, Line: 4}, explanation.Origin)
+	assert.Empty(t, explanation.Shadowed)
+
+	_, err = cfg.Explain("server.missing")
+	require.ErrorIs(t, err, errors.ErrKeyNotFound)
+}
+
+func TestConfig_WithLayerOrder(t *testing.T) {
+	t.Setenv("ORDER_APP_NAME", "from-env")
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.json", `{"app": {"name": "from-file"}}`)
+
+	cfg := config.New(config.WithLayerOrder(contract.LayerDefaults, contract.LayerEnv, contract.LayerFiles))
+	require.NoError(t, cfg.FileLoader().LoadFromFile(path))
+	require.NoError(t, cfg.EnvLoader().LoadFromEnv("ORDER"))
+	require.NoError(t, cfg.Reload())
+
+	assert.Equal(t, "from-file", config.MustGet[string](cfg, "app.name"))
+
+	source, err := cfg.Source("app.name")
+	require.NoError(t, err)
+	assert.Equal(t, contract.LayerFiles, source)
+
+	// Overrides are not part of the configured order, so they are ignored.
+	cfg.Set("app.name", "override")
+	assert.Equal(t, "from-file", config.MustGet[string](cfg, "app.name"))
+}
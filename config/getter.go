@@ -45,6 +45,25 @@ func (g *Getter) Get(key string, typ contract.KeyType) (any, error) {
 	return val, nil
 }
 
+// Lookup returns the raw, unconverted value stored at key.
+// Like Get it tries a flat lookup first and falls back to dot notation.
+func (g *Getter) Lookup(key string) (any, error) {
+	if key == "" || g.config == nil {
+		return nil, errors.ErrKeyNotFound
+	}
+
+	if val, ok := g.config[key]; ok {
+		return val, nil
+	}
+
+	val := dotmap.Resolve(g.config, key)
+	if val == nil {
+		return nil, errors.ErrKeyNotFound
+	}
+
+	return val, nil
+}
+
 func (g *Getter) GetKey(key string) any {
 	val, _ := g.Get(key, contract.String)
 
@@ -154,6 +173,11 @@ func (g *Getter) HasKey(key string) bool {
 	return dotmap.Resolve(g.config, key) != nil
 }
 
+// Has reports whether key exists; it lets a Getter serve as a contract.ValueAccessor.
+func (g *Getter) Has(key string) bool {
+	return g.HasKey(key)
+}
+
 // TypeConverter defines a function that converts a value to a specific type.
 type TypeConverter func(val any) (any, error)
 
@@ -283,3 +307,6 @@ func tryTypeCast(val any, typ contract.KeyType) (any, error) {
 
 	return value, nil
 }
+
+// Compile time checks for interface.
+var _ contract.ValueAccessor = (*Getter)(nil)
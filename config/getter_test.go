@@ -65,6 +65,8 @@ func TestGetter_Get(t *testing.T) {
 		{"String flat", "bar", contract.String, "abc", false},
 		{"Bool flat", "baz", contract.Bool, true, false},
 		{"Float64 flat", "pi", contract.Float64, 3.14, false},
+		{"Float32 from int", "foo", contract.Float32, float32(123), false},
+		{"Float32 from int64", "big", contract.Float32, float32(9876543210), false},
 		{"Time flat", "now", contract.Time, timeStamp, false},
 		{"Duration flat", "duration", contract.Duration, 2 * time.Second, false},
 		{"Int64 flat", "big", contract.Int64, int64(9876543210), false},
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/dotmap"
+)
+
+// knownKeysSetter is implemented by loaders that map their sources onto keys the
+// config already knows, such as env.Loader created WithKeyMatching.
+type knownKeysSetter interface {
+	SetKnownKeys(known func() []string)
+}
+
+// keyTypesSetter is implemented by loaders that convert raw values to the type of the
+// key they set, such as env.Loader created WithTypeCoercion.
+type keyTypesSetter interface {
+	SetKeyTypes(types func() map[string]contract.KeyType)
+}
+
+// knownLayers holds the flattened values of the defaults and flags layers as of the
+// last publish, for knownKeys and keyTypes, which run without c.mu.
+type knownLayers struct {
+	defaults map[string]any
+	flags    map[string]any
+}
+
+// knownKeys lists every key of the defaults, files and flags layers, and every key
+// declared by a schema. The file layer may not have been published yet. Loaders call
+// it while the config may be reloading, so it does not take c.mu.
+func (c *Config) knownKeys() []string {
+	keys := c.knownValues()
+
+	for _, schema := range c.loadSchemas() {
+		if lister, ok := schema.(contract.KeyLister); ok {
+			for _, key := range lister.Keys() {
+				keys[key] = nil
+			}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(keys))
+}
+
+// keyTypes returns the type of every known key whose type can be told: the type
+// declared by a schema, or else the type of the value the key currently holds. Like
+// knownKeys, it does not take c.mu.
+func (c *Config) keyTypes() map[string]contract.KeyType {
+	types := make(map[string]contract.KeyType)
+
+	for key, value := range c.knownValues() {
+		if typ, ok := valueType(value); ok {
+			types[key] = typ
+		}
+	}
+
+	for _, schema := range c.loadSchemas() {
+		if typer, ok := schema.(contract.KeyTyper); ok {
+			maps.Copy(types, typer.KeyTypes())
+		}
+	}
+
+	return types
+}
+
+// knownValues flattens the defaults, files and flags layers into one map. The env and
+// overrides layers are left out, so a key an env variable created never shadows the key
+// a file adds later. During a reload the file layer is read from its stage.
+func (c *Config) knownValues() map[string]any {
+	values := make(map[string]any)
+
+	known := c.known.Load()
+	if known != nil {
+		maps.Copy(values, known.defaults)
+	}
+
+	files := c.fileLoader.GetProvider()
+	if staged := c.stagedFiles.Load(); staged != nil {
+		files = *staged
+	}
+
+	maps.Copy(values, dotmap.Flatten(files.AllSettings()))
+
+	if known != nil {
+		maps.Copy(values, known.flags)
+	}
+
+	return values
+}
+
+// publishKnown records the values of the defaults and flags layers for knownValues.
+// Assumes c.mu is held.
+func (c *Config) publishKnown() {
+	c.known.Store(&knownLayers{
+		defaults: dotmap.Flatten(c.defaults.AllSettings()),
+		flags:    dotmap.Flatten(c.flags.AllSettings()),
+	})
+}
+
+// loadSchemas returns the registered validators without taking c.mu.
+func (c *Config) loadSchemas() []contract.Validator {
+	if schemas := c.schemas.Load(); schemas != nil {
+		return *schemas
+	}
+
+	return nil
+}
+
+// valueType returns the KeyType that describes value. Strings report no type, since
+// there is nothing to convert them to.
+func valueType(value any) (contract.KeyType, bool) {
+	switch value.(type) {
+	case int:
+		return contract.Int, true
+	case int32:
+		return contract.Int32, true
+	case int64:
+		return contract.Int64, true
+	case uint:
+		return contract.Uint, true
+	case uint32:
+		return contract.Uint32, true
+	case uint64:
+		return contract.Uint64, true
+	case float32:
+		return contract.Float32, true
+	case float64:
+		return contract.Float64, true
+	case bool:
+		return contract.Bool, true
+	case []any, []string:
+		return contract.StringSlice, true
+	case map[string]any:
+		return contract.Map, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"flag"
+	"slices"
+	"strings"
+
+	"github.com/hbttundar/scg-config/contract"
+)
+
+// defaultLayerOrder lists the layers from lowest to highest precedence.
+//
+//nolint:gochecknoglobals // read-only default, copied into every Config
+var defaultLayerOrder = []contract.Layer{
+	contract.LayerDefaults,
+	contract.LayerFiles,
+	contract.LayerEnv,
+	contract.LayerFlags,
+	contract.LayerOverrides,
+}
+
+// WithLayerOrder sets the layer precedence from lowest to highest.
+// Layers that are not listed are ignored when resolving values.
+func WithLayerOrder(order ...contract.Layer) Option {
+	return func(c *Config) { c.order = slices.Clone(order) }
+}
+
+// layer is one source of values together with whatever knows where they came from.
+type layer struct {
+	name     contract.Layer
+	provider contract.Provider
+	origins  contract.OriginTracker
+}
+
+// origin returns where key came from within this layer.
+func (l layer) origin(key string) contract.Origin {
+	if l.origins != nil {
+		if origin, ok := l.origins.Origin(key); ok {
+			return origin
+		}
+	}
+
+	return contract.Origin{Layer: l.name}
+}
+
+// originMap is an OriginTracker backed by a plain map.
+type originMap map[string]contract.Origin
+
+func (m originMap) Origin(key string) (contract.Origin, bool) {
+	origin, ok := m[key]
+
+	return origin, ok
+}
+
+// layers returns the configured layers from lowest to highest precedence. A provider
+// shared by several layers (e.g. a loader built on the main provider) is only kept at
+// its highest position.
+func (c *Config) layers() []layer {
+	all := make([]layer, 0, len(c.order))
+
+	for _, name := range c.order {
+		if l, ok := c.layer(name); ok && l.provider != nil {
+			all = append(all, l)
+		}
+	}
+
+	seen := make(map[contract.Provider]bool, len(all))
+	result := make([]layer, 0, len(all))
+
+	for _, l := range slices.Backward(all) {
+		if seen[l.provider] {
+			continue
+		}
+
+		seen[l.provider] = true
+		result = append(result, l)
+	}
+
+	slices.Reverse(result)
+
+	return result
+}
+
+// layer builds the layer with the given name from the config's providers and loaders.
+func (c *Config) layer(name contract.Layer) (layer, bool) {
+	switch name {
+	case contract.LayerDefaults:
+		return layer{name: name, provider: c.defaults, origins: nil}, true
+	case contract.LayerFiles:
+		return loaderLayer(name, c.fileLoader), c.fileLoader != nil
+	case contract.LayerEnv:
+		return loaderLayer(name, c.envLoader), c.envLoader != nil
+	case contract.LayerFlags:
+		return layer{name: name, provider: c.flags, origins: c.flagOrigins}, true
+	case contract.LayerOverrides:
+		return layer{name: name, provider: c.provider, origins: nil}, true
+	default:
+		return layer{}, false
+	}
+}
+
+// loaderLayer builds a layer from a file or env loader.
+func loaderLayer(name contract.Layer, loader interface{ GetProvider() contract.Provider }) layer {
+	if loader == nil {
+		return layer{name: name, provider: nil, origins: nil}
+	}
+
+	tracker, _ := loader.(contract.OriginTracker)
+
+	return layer{name: name, provider: loader.GetProvider(), origins: tracker}
+}
+
+// settings merges every layer, lowest precedence first.
+func (c *Config) settings() map[string]any {
+	return c.mergeLayers(nil)
+}
+
+// settingsWith merges every layer like settings, as it will be once stages are
+// committed.
+func (c *Config) settingsWith(stages map[contract.Layer]contract.Stage) (map[string]any, error) {
+	staged, err := c.stagedProviders(stages)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.mergeLayers(staged), nil
+}
+
+// mergeLayers merges every layer, lowest precedence first, reading a layer from the
+// provider staged in place of its own when there is one.
+func (c *Config) mergeLayers(staged map[contract.Provider]contract.Provider) map[string]any {
+	merged := make(map[string]any)
+
+	for _, l := range c.layers() {
+		provider := l.provider
+		if stagedProvider, ok := staged[l.provider]; ok {
+			provider = stagedProvider
+		}
+
+		mergeSettings(merged, provider.AllSettings())
+	}
+
+	return merged
+}
+
+// mergeSettings deep-merges src into dst; nested maps are merged, anything else replaces.
+func mergeSettings(dst, src map[string]any) {
+	for key, srcVal := range src {
+		srcMap, srcIsMap := srcVal.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+
+		if srcIsMap && dstIsMap {
+			mergeSettings(dstMap, srcMap)
+
+			continue
+		}
+
+		dst[key] = srcVal
+	}
+}
+
+// Set overrides key at runtime. Overrides have the highest precedence by default.
+func (c *Config) Set(key string, value any) {
+	c.mu.Lock()
+	c.provider.Set(key, value)
+	ch := c.publish(c.settings())
+	c.mu.Unlock()
+
+	c.notify(ch)
+}
+
+// LoadFlags copies every flag that was set on the command line into the flags layer.
+// The flag name is used as the key, so a flag named "server.port" sets server.port.
+func (c *Config) LoadFlags(flags *flag.FlagSet) {
+	c.mu.Lock()
+
+	flags.Visit(func(f *flag.Flag) {
+		var value any = f.Value.String()
+		if getter, ok := f.Value.(flag.Getter); ok {
+			value = getter.Get()
+		}
+
+		c.flags.Set(f.Name, value)
+		c.flagOrigins[strings.ToLower(f.Name)] = contract.Origin{Layer: contract.LayerFlags, Flag: f.Name}
+	})
+
+	ch := c.publish(c.settings())
+	c.mu.Unlock()
+
+	c.notify(ch)
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"slices"
+)
+
+// ChangeListener is called after a new snapshot has been published. changedKeys lists
+// the dotted paths that were added, removed or modified, in path order (see Snapshot.Diff).
+type ChangeListener func(previous, current *Snapshot, changedKeys []string)
+
+// change is a published snapshot together with the one it replaced.
+type change struct {
+	previous *Snapshot
+	current  *Snapshot
+}
+
+// OnChange registers listener to be called after every reload, Set, SetDefault or
+// LoadFlags that changes at least one key. Listeners run synchronously, in
+// registration order, on the goroutine that triggered the change.
+func (c *Config) OnChange(listener ChangeListener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.listeners = append(c.listeners, listener)
+}
+
+// OnError registers handler for errors that happen in the background while watching
+// files, where there is no caller to return them to. Use errors.Is to tell them apart:
+//
+//   - errors.ErrWatchFailed: the file system watch itself failed.
+//   - errors.ErrReadConfigFileFailed: a changed file could not be read; it also matches
+//     errors.ErrParseConfigFileFailed when the file exists but is not valid.
+//   - errors.ErrValidationFailed: the new config was rejected by a schema.
+//
+// In every case the previous snapshot keeps being served.
+func (c *Config) OnError(handler func(error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errorHandlers = append(c.errorHandlers, handler)
+}
+
+// reportError passes err to every OnError handler. It must be called without c.mu held.
+func (c *Config) reportError(err error) {
+	c.mu.RLock()
+	handlers := slices.Clone(c.errorHandlers)
+	c.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(err)
+	}
+}
+
+// notify tells every listener about ch. It must be called without c.mu held so that
+// listeners are free to read from the Config.
+func (c *Config) notify(ch change) {
+	if ch.previous == nil {
+		return
+	}
+
+	keys := changedKeys(ch.previous, ch.current)
+	if len(keys) == 0 {
+		return
+	}
+
+	c.mu.RLock()
+	listeners := slices.Clone(c.listeners)
+	c.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(ch.previous, ch.current, keys)
+	}
+}
+
+// changedKeys returns the paths that differ between two snapshots, as reported by
+// dotmap.Diff.
+func changedKeys(previous, current *Snapshot) []string {
+	changes := previous.Diff(current)
+
+	keys := make([]string, len(changes))
+	for idx, change := range changes {
+		keys[idx] = change.Path
+	}
+
+	return keys
+}
@@ -0,0 +1,316 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/loader/file"
+	"github.com/hbttundar/scg-config/provider/viper"
+	"github.com/hbttundar/scg-config/schema"
+	"github.com/hbttundar/scg-config/watcher"
+)
+
+type changeEvent struct {
+	previous, current uint64
+	keys              []string
+}
+
+func TestConfig_OnChange(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "app:\n  name: one\n  port: 80\n")
+
+	cfg := config.New()
+	require.NoError(t, cfg.FileLoader().LoadFromFile(path))
+	require.NoError(t, cfg.Reload())
+
+	var events []changeEvent
+
+	cfg.OnChange(func(previous, current *config.Snapshot, changedKeys []string) {
+		assert.Equal(t, "two", config.MustGet[string](cfg, "app.name"), "listener must see the new snapshot")
+		events = append(events, changeEvent{previous: previous.Version(), current: current.Version(), keys: changedKeys})
+	})
+
+	writeFile(t, dir, "app.yaml", "app:\n  name: two\n  debug: true\n")
+	require.NoError(t, cfg.Reload())
+	require.Len(t, events, 1)
+	assert.Equal(t, []string{"app.debug", "app.name", "app.port"}, events[0].keys)
+	assert.Equal(t, events[0].previous+1, events[0].current)
+
+	// Nothing changed, so nobody is notified.
+	require.NoError(t, cfg.Reload())
+	cfg.Set("app.name", "two")
+	assert.Len(t, events, 1)
+}
+
+func TestConfig_OnChangeSkipsRejectedReload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "port: 80\n")
+
+	portSchema := schema.New()
+	portSchema.Field("port").Max(1024)
+
+	cfg := config.New(config.WithSchema(portSchema))
+	require.NoError(t, cfg.FileLoader().LoadFromFile(path))
+	require.NoError(t, cfg.Reload())
+
+	calls := 0
+
+	cfg.OnChange(func(_, _ *config.Snapshot, _ []string) { calls++ })
+
+	writeFile(t, dir, "app.yaml", "port: 8080\n")
+	require.Error(t, cfg.Reload())
+	assert.Zero(t, calls)
+
+	cfg.Set("port", 443)
+	assert.Equal(t, 1, calls)
+}
+
+func TestConfig_StartWatchingReloads(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "app:\n  name: before\n")
+
+	cfg := config.New()
+	require.NoError(t, cfg.FileLoader().LoadFromFile(path))
+	require.NoError(t, cfg.Reload())
+
+	defer func() { _ = cfg.Close() }()
+
+	var notified atomic.Int32
+
+	cfg.OnChange(func(_, _ *config.Snapshot, _ []string) { notified.Add(1) })
+	require.NoError(t, cfg.StartWatching(path))
+
+	require.NoError(t, os.WriteFile(path, []byte("app:\n  name: after\n"), 0o600))
+
+	require.Eventually(t, func() bool {
+		name, err := cfg.Lookup("app.name")
+
+		return err == nil && name == "after"
+	}, 2*time.Second, 10*time.Millisecond, "config was not reloaded after the file changed")
+	assert.Positive(t, notified.Load())
+}
+
+func TestConfig_StartWatchingProviderConfigFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "app:\n  name: before\n")
+
+	// The file is read by the provider itself rather than by the file loader.
+	provider := viper.NewConfigProvider()
+	provider.SetConfigFile(path)
+	require.NoError(t, provider.ReadInConfig())
+
+	cfg := config.New(config.WithProvider(provider))
+
+	defer func() { _ = cfg.Close() }()
+
+	require.NoError(t, cfg.StartWatching(path))
+	require.NoError(t, os.WriteFile(path, []byte("app:\n  name: after\n"), 0o600))
+
+	require.Eventually(t, func() bool {
+		name, err := cfg.Lookup("app.name")
+
+		return err == nil && name == "after"
+	}, 2*time.Second, 10*time.Millisecond, "config was not reloaded after the file changed")
+}
+
+func TestConfig_StartWatchingCoalescesFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	first := writeFile(t, dir, "a.yaml", "a: 1\n")
+	second := writeFile(t, dir, "b.yaml", "b: 1\n")
+
+	cfg := config.New()
+	require.NoError(t, cfg.FileLoader().LoadFromDirectory(dir))
+	require.NoError(t, cfg.Reload())
+
+	defer func() { _ = cfg.Close() }()
+
+	var notified atomic.Int32
+
+	cfg.OnChange(func(_, _ *config.Snapshot, _ []string) { notified.Add(1) })
+	require.NoError(t, cfg.StartWatching(first))
+	require.NoError(t, cfg.StartWatching(second))
+
+	writeFile(t, dir, "a.yaml", "a: 2\n")
+	writeFile(t, dir, "b.yaml", "b: 2\n")
+
+	require.Eventually(t, func() bool {
+		b, err := cfg.Lookup("b")
+
+		return err == nil && b == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, config.MustGet[int](cfg, "a"))
+	assert.Equal(t, int32(1), notified.Load(), "both files must be picked up by one reload")
+}
+
+func TestConfig_OnErrorReportsRejectedReloads(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "port: 80\n")
+
+	portSchema := schema.New()
+	portSchema.Field("port").Required().Max(1024)
+
+	cfg := config.New(config.WithSchema(portSchema))
+	require.NoError(t, cfg.FileLoader().LoadFromFile(path))
+	require.NoError(t, cfg.Reload())
+
+	defer func() { _ = cfg.Close() }()
+
+	reported := make(chan error, 8)
+
+	cfg.OnError(func(err error) { reported <- err })
+	require.NoError(t, cfg.StartWatching(path))
+
+	nextError := func() error {
+		t.Helper()
+
+		select {
+		case err := <-reported:
+			return err
+		case <-time.After(2 * time.Second):
+			t.Fatal("reload error was not reported")
+
+			return nil
+		}
+	}
+
+	writeFile(t, dir, "app.yaml", "port: [80\n")
+
+	err := nextError()
+	require.ErrorIs(t, err, errors.ErrReadConfigFileFailed)
+	require.ErrorIs(t, err, errors.ErrParseConfigFileFailed)
+	assert.Equal(t, 80, config.MustGet[int](cfg, "port"))
+
+	writeFile(t, dir, "app.yaml", "port: 8080\n")
+
+	err = nextError()
+	require.ErrorIs(t, err, errors.ErrValidationFailed)
+	require.NotErrorIs(t, err, errors.ErrParseConfigFileFailed)
+	assert.Equal(t, 80, config.MustGet[int](cfg, "port"))
+}
+
+func TestConfig_WatchDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "app.yaml", "app:\n  name: base\n")
+
+	cfg := config.New()
+	require.NoError(t, cfg.FileLoader().LoadFromDirectory(dir))
+	require.NoError(t, cfg.Reload())
+
+	defer func() { _ = cfg.Close() }()
+
+	require.NoError(t, cfg.WatchDirectory(dir))
+
+	lookup := func(key string) func() bool {
+		return func() bool {
+			_, err := cfg.Lookup(key)
+
+			return err == nil
+		}
+	}
+
+	writeFile(t, dir, "cache.yaml", "cache:\n  ttl: 60\n")
+	require.Eventually(t, lookup("cache.ttl"), 2*time.Second, 10*time.Millisecond, "added file was not merged")
+
+	// Files merge alphabetically, so zz.yaml overrides app.yaml.
+	writeFile(t, dir, "zz.yaml", "app:\n  name: override\n")
+	require.Eventually(t, func() bool {
+		name, err := cfg.Lookup("app.name")
+
+		return err == nil && name == "override"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "cache.yaml")))
+	require.Eventually(t, func() bool { return !lookup("cache.ttl")() }, 2*time.Second, 10*time.Millisecond,
+		"removed file was not dropped")
+	assert.Equal(t, "override", config.MustGet[string](cfg, "app.name"))
+}
+
+func TestConfig_WatchDirectoryRecursive(t *testing.T) {
+	t.Parallel()
+
+	watchers := map[string]func() contract.Watcher{
+		"fsnotify": func() contract.Watcher { return watcher.NewWatcher(nil, watcher.WithDebounce(20*time.Millisecond)) },
+		"polling":  func() contract.Watcher { return watcher.NewPollingWatcher(20 * time.Millisecond) },
+	}
+
+	for name, newWatcher := range watchers {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			writeFile(t, dir, "app.yaml", "name: base\n")
+
+			loader := file.NewFileLoader(viper.NewConfigProvider(),
+				file.WithNamespaceByFilename(), file.WithExclude("*.example.yaml"))
+			cfg := config.New(config.WithFileLoader(loader), config.WithWatcher(newWatcher()))
+			require.NoError(t, loader.LoadFromDirectory(dir))
+			require.NoError(t, cfg.Reload())
+
+			defer func() { _ = cfg.Close() }()
+
+			require.NoError(t, cfg.WatchDirectory(dir))
+
+			// A file in a subdirectory created after watching started is picked up.
+			require.NoError(t, os.Mkdir(filepath.Join(dir, "services"), 0o750))
+			writeFile(t, dir, "services/billing.yaml", "rate: 5\n")
+			require.Eventually(t, func() bool {
+				rate, err := cfg.Lookup("services.billing.rate")
+
+				return err == nil && rate == 5
+			}, 2*time.Second, 10*time.Millisecond, "file in a new subdirectory was not merged")
+
+			writeFile(t, dir, "services/billing.yaml", "rate: 7\n")
+			require.Eventually(t, func() bool {
+				rate, err := cfg.Lookup("services.billing.rate")
+
+				return err == nil && rate == 7
+			}, 2*time.Second, 10*time.Millisecond, "edit in a subdirectory was not merged")
+
+			// Excluded files are not loaded, so editing them does not reload.
+			version := cfg.Snapshot().Version()
+
+			writeFile(t, dir, "app.example.yaml", "name: example\n")
+			time.Sleep(200 * time.Millisecond)
+			assert.Equal(t, version, cfg.Snapshot().Version())
+		})
+	}
+}
+
+// fileWatcher is a contract.Watcher that cannot watch directories.
+type fileWatcher struct{}
+
+func (fileWatcher) AddFile(string, func()) error { return nil }
+func (fileWatcher) Watch(func())                 {}
+func (fileWatcher) OnError(func(error))          {}
+func (fileWatcher) Close() error                 { return nil }
+
+func TestConfig_WatchDirectoryUnsupported(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New(config.WithWatcher(fileWatcher{}))
+
+	require.ErrorIs(t, cfg.WatchDirectory(t.TempDir()), errors.ErrWatchDirectoryNotSupported)
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+)
+
+// DefaultProfileEnv is the environment variable LoadProfile reads the active profiles
+// from when none are passed.
+const DefaultProfileEnv = "APP_PROFILE"
+
+// WithProfileEnv sets the environment variable LoadProfile reads the active profiles
+// from, instead of DefaultProfileEnv.
+func WithProfileEnv(name string) Option {
+	return func(c *Config) { c.profileEnv = name }
+}
+
+// envSourcer is implemented by env loaders that read from an injectable source, such
+// as env.Loader.
+type envSourcer interface {
+	Source() contract.EnvSource
+}
+
+// LoadProfile loads the base files of dir and then the overlays of each profile, e.g.
+// app.yaml followed by app.staging.yaml (see file.Loader.LoadProfile), and publishes
+// the result. When no profiles are passed they are read from the APP_PROFILE
+// environment variable (see WithProfileEnv) as a comma-separated list such as
+// "staging,local"; if it is unset only the base files are loaded. Explain reports the
+// overlay that set a value in its Origin.Profile.
+//
+// Like Reload, the profile is only applied if the merged result passes validation;
+// otherwise the previous configuration stays in place.
+func (c *Config) LoadProfile(dir string, profiles ...string) error {
+	loader, ok := c.fileLoader.(contract.ProfileLoader)
+	if !ok {
+		return errors.ErrProfilesNotSupported
+	}
+
+	if len(profiles) == 0 {
+		profiles = c.envProfiles()
+	}
+
+	c.mu.Lock()
+	ch, err := c.applyStaged(contract.LayerFiles, func() (contract.Stage, error) {
+		return loader.StageProfile(dir, profiles...)
+	})
+	c.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("error loading profiles %v from %s: %w", profiles, dir, err)
+	}
+
+	c.notify(ch)
+
+	return nil
+}
+
+// envProfiles returns the profiles listed in the profile environment variable, read
+// from the env loader's source when it has one.
+func (c *Config) envProfiles() []string {
+	lookup := os.LookupEnv
+	if sourcer, ok := c.envLoader.(envSourcer); ok {
+		lookup = sourcer.Source().LookupEnv
+	}
+
+	value, _ := lookup(c.profileEnv)
+
+	var profiles []string
+
+	for _, profile := range strings.Split(value, ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles
+}
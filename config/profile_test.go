@@ -0,0 +1,137 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/loader/env"
+	"github.com/hbttundar/scg-config/loader/file"
+	"github.com/hbttundar/scg-config/provider/viper"
+	"github.com/hbttundar/scg-config/schema"
+)
+
+func writeProfiles(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "app.yaml", "app:\n  name: base\n  port: 8080\n")
+	writeFile(t, dir, "app.staging.yaml", "app:\n  name: staging\n")
+	writeFile(t, dir, "app.production.yaml", "app:\n  name: production\n")
+	writeFile(t, dir, "app.local.yaml", "app:\n  port: 7070\n")
+
+	return dir
+}
+
+func TestConfig_LoadProfileFromEnvSource(t *testing.T) {
+	t.Parallel()
+
+	loader := env.NewEnvLoader(viper.NewConfigProvider(), env.WithEnvMap(map[string]string{"APP_PROFILE": "production"}))
+	cfg := config.New(config.WithEnvLoader(loader))
+	require.NoError(t, cfg.LoadProfile(writeProfiles(t)))
+
+	assert.Equal(t, "production", config.MustGet[string](cfg, "app.name"))
+}
+
+func TestConfig_LoadProfileFromEnv(t *testing.T) {
+	t.Setenv("SCG_TEST_PROFILE", "staging, local")
+
+	cfg := config.New(config.WithProfileEnv("SCG_TEST_PROFILE"))
+	require.NoError(t, cfg.LoadProfile(writeProfiles(t)))
+
+	assert.Equal(t, "staging", config.MustGet[string](cfg, "app.name"))
+	assert.Equal(t, 7070, config.MustGet[int](cfg, "app.port"))
+
+	explanation, err := cfg.Explain("app.port")
+	require.NoError(t, err)
+	assert.Equal(t, contract.LayerFiles, explanation.Origin.Layer)
+	assert.Equal(t, "local", explanation.Origin.Profile)
+	assert.Contains(t, explanation.Origin.File, "app.local.yaml")
+}
+
+func TestConfig_LoadProfile(t *testing.T) {
+	t.Parallel()
+
+	var changed []string
+
+	cfg := config.New()
+	cfg.OnChange(func(_, _ *config.Snapshot, changedKeys []string) { changed = changedKeys })
+
+	require.NoError(t, cfg.LoadProfile(writeProfiles(t), "production"))
+
+	assert.Equal(t, "production", config.MustGet[string](cfg, "app.name"))
+	assert.Equal(t, 8080, config.MustGet[int](cfg, "app.port"))
+	assert.Equal(t, []string{"app"}, changed)
+
+	explanation, err := cfg.Explain("app.port")
+	require.NoError(t, err)
+	assert.Empty(t, explanation.Origin.Profile, "app.port comes from the base file")
+
+	// Profiles are re-applied on reload.
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, "production", config.MustGet[string](cfg, "app.name"))
+}
+
+func TestConfig_LoadProfileRejectedByValidation(t *testing.T) {
+	t.Parallel()
+
+	dir := writeProfiles(t)
+	writeFile(t, dir, "app.invalid.yaml", "app:\n  port: 99999\n")
+
+	portSchema := schema.New()
+	portSchema.Field("app.port").Max(65535)
+
+	cfg := config.New(config.WithSchema(portSchema))
+	require.NoError(t, cfg.LoadProfile(dir, "staging"))
+
+	require.ErrorIs(t, cfg.LoadProfile(dir, "invalid"), errors.ErrValidationFailed)
+	assert.Equal(t, 8080, config.MustGet[int](cfg, "app.port"))
+	assert.Equal(t, 8080, cfg.FileLoader().GetProvider().GetKey("app.port"))
+
+	// The rejected profile is not remembered, so reloads keep working.
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, 8080, config.MustGet[int](cfg, "app.port"))
+	assert.Equal(t, "staging", config.MustGet[string](cfg, "app.name"))
+}
+
+func TestConfig_LoadProfileSharedProviderRejectedByValidation(t *testing.T) {
+	t.Parallel()
+
+	dir := writeProfiles(t)
+	writeFile(t, dir, "app.invalid.yaml", "app:\n  port: 99999\n")
+
+	portSchema := schema.New()
+	portSchema.Field("app.port").Max(65535)
+
+	// The file loader shares the overrides provider, so the profile cannot be swapped in.
+	provider := viper.NewConfigProvider()
+	cfg := config.New(
+		config.WithProvider(provider),
+		config.WithFileLoader(file.NewFileLoader(provider)),
+		config.WithSchema(portSchema),
+	)
+	require.NoError(t, cfg.LoadProfile(dir, "staging"))
+
+	require.ErrorIs(t, cfg.LoadProfile(dir, "invalid"), errors.ErrValidationFailed)
+	assert.Equal(t, 8080, provider.GetKey("app.port"))
+
+	cfg.Set("x", 1)
+	assert.Equal(t, 8080, config.MustGet[int](cfg, "app.port"))
+	assert.Equal(t, "staging", config.MustGet[string](cfg, "app.name"))
+}
+
+// plainFileLoader is a FileLoader without profile support.
+type plainFileLoader struct{ contract.FileLoader }
+
+func TestConfig_LoadProfileUnsupported(t *testing.T) {
+	t.Parallel()
+
+	loader := plainFileLoader{FileLoader: file.NewFileLoader(viper.NewConfigProvider())}
+	cfg := config.New(config.WithFileLoader(loader))
+
+	require.ErrorIs(t, cfg.LoadProfile(t.TempDir(), "staging"), errors.ErrProfilesNotSupported)
+}
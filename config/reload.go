@@ -0,0 +1,337 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/internal/stage"
+	"github.com/hbttundar/scg-config/provider/viper"
+)
+
+// Reload re-reads every loaded source, including the config file the provider passed to
+// WithProvider read, and publishes a new snapshot of the merged layers.
+//
+// Reloads are transactional: sources are read into fresh providers first, and nothing
+// is replaced unless every file parses and the merged result passes validation. A
+// loader whose provider is shared with another layer is reloaded into a copy of that
+// provider first (see contract.Cloner) and written into it only then. A failed
+// reload keeps serving the previous snapshot and is recorded in LastReloadError.
+// Listeners registered with OnChange are notified after a successful reload that
+// changed at least one key.
+func (c *Config) Reload() error {
+	ch, err := c.reload()
+	if err != nil {
+		return err
+	}
+
+	c.notify(ch)
+
+	return nil
+}
+
+// LastReloadError returns the error of the most recent reload, or nil if it succeeded
+// or no reload has happened yet.
+func (c *Config) LastReloadError() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.lastReloadErr
+}
+
+// LastGoodAt returns when the most recent successful reload was published, or the zero
+// time if no reload has succeeded yet.
+func (c *Config) LastGoodAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.lastGoodAt
+}
+
+// reload is Reload without the notification, run under the write lock.
+func (c *Config) reload() (change, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch, err := c.reloadLocked()
+
+	c.lastReloadErr = err
+	if err != nil {
+		return change{}, err
+	}
+
+	c.lastGoodAt = ch.current.LoadedAt()
+
+	return ch, nil
+}
+
+// reloadLocked re-reads every source through readSourcesLocked and publishes the
+// result. Assumes c.mu is held.
+func (c *Config) reloadLocked() (change, error) {
+	settings, err := c.readSourcesLocked()
+	if err != nil {
+		return change{}, fmt.Errorf("error reloading config: %w", err)
+	}
+
+	return c.publish(settings), nil
+}
+
+// readSourcesLocked stages every source, validates the merged result and only then
+// commits the stages, returning the merged settings. Nothing is committed when a source
+// fails to read or the result is invalid. Assumes c.mu is held.
+func (c *Config) readSourcesLocked() (map[string]any, error) {
+	stages, err := c.stageSources()
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := c.settingsWith(stages)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.validate(settings); err != nil {
+		return nil, err
+	}
+
+	if err := c.commitStages(stages); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// stageSources re-reads the sources of the file and env loaders. A loader that supports
+// staging reads into a fresh provider, returned keyed by its layer; other loaders are
+// re-read in place. Files are read first, and the env loader matches its variables
+// against the keys of the staged files. Assumes c.mu is held.
+func (c *Config) stageSources() (map[contract.Layer]contract.Stage, error) {
+	stages := make(map[contract.Layer]contract.Stage)
+
+	defer c.stagedFiles.Store(nil)
+
+	loaders := []struct {
+		name   contract.Layer
+		loader any
+	}{
+		{name: contract.LayerFiles, loader: c.fileLoader},
+		{name: contract.LayerEnv, loader: c.envLoader},
+	}
+
+	for _, entry := range loaders {
+		if entry.loader == nil {
+			continue
+		}
+
+		if stager, ok := entry.loader.(contract.StagedReloader); ok {
+			pending, err := stager.StageReload()
+			if err != nil {
+				return nil, fmt.Errorf("error re-reading sources: %w", err)
+			}
+
+			stages[entry.name] = pending
+			if entry.name == contract.LayerFiles {
+				provider := pending.Provider()
+				c.stagedFiles.Store(&provider)
+			}
+
+			continue
+		}
+
+		if reloader, ok := entry.loader.(contract.Reloader); ok {
+			if err := reloader.Reload(); err != nil {
+				return nil, fmt.Errorf("error re-reading sources: %w", err)
+			}
+		}
+	}
+
+	overrides, ok, err := c.stageOverrides()
+	if err != nil {
+		return nil, fmt.Errorf("error re-reading sources: %w", err)
+	}
+
+	if ok {
+		stages[contract.LayerOverrides] = overrides
+	}
+
+	return stages, nil
+}
+
+// configFileReader is implemented by providers that remember the config file they
+// read, such as the Viper provider.
+type configFileReader interface {
+	ConfigFileUsed() string
+}
+
+// configReplacer is implemented by providers that can swap their file config for an
+// already decoded map, such as the Viper provider.
+type configReplacer interface {
+	ReplaceConfig(settings map[string]any) error
+}
+
+// stageOverrides re-reads the config file the overrides provider read itself, such as
+// a provider passed to WithProvider after SetConfigFile and ReadInConfig, into a fresh
+// provider. Committing the stage replaces the provider's file config and keeps the
+// values set with Set. A provider shared with a loader has its file config re-read by
+// the loader instead. Assumes c.mu is held.
+func (c *Config) stageOverrides() (contract.Stage, bool, error) {
+	reader, ok := c.provider.(configFileReader)
+	if !ok || reader.ConfigFileUsed() == "" || c.sharedProvider(c.provider) {
+		return nil, false, nil
+	}
+
+	_, canReplace := c.provider.(configReplacer)
+	if _, canClone := c.provider.(contract.Cloner); !canReplace || !canClone {
+		return nil, false, nil
+	}
+
+	provider := viper.NewConfigProvider()
+	provider.SetConfigFile(reader.ConfigFileUsed())
+
+	if err := provider.ReadInConfig(); err != nil {
+		return nil, false, fmt.Errorf("error re-reading %s: %w", reader.ConfigFileUsed(), err)
+	}
+
+	// The overrides provider belongs to the caller, so there is no loader to update
+	keep := func(contract.Provider, map[string]contract.Origin) {}
+
+	return stage.New(provider, nil, keep, replaceFileConfig), true, nil
+}
+
+// replaceFileConfig makes the file config of staged the file config of target.
+func replaceFileConfig(staged, target contract.Provider) error {
+	replacer, ok := target.(configReplacer)
+	if !ok {
+		return errors.ErrSharedProviderNotStageable
+	}
+
+	if err := replacer.ReplaceConfig(staged.AllSettings()); err != nil {
+		return fmt.Errorf("error replacing config file: %w", err)
+	}
+
+	return nil
+}
+
+// applyStaged validates the change the stage from stageFn would make to the given layer
+// and only then commits and publishes it. Assumes c.mu is held.
+func (c *Config) applyStaged(name contract.Layer, stageFn func() (contract.Stage, error)) (change, error) {
+	staged, err := stageFn()
+	if err != nil {
+		return change{}, err
+	}
+
+	stages := map[contract.Layer]contract.Stage{name: staged}
+
+	settings, err := c.settingsWith(stages)
+	if err != nil {
+		return change{}, err
+	}
+
+	if err := c.validate(settings); err != nil {
+		return change{}, err
+	}
+
+	if err := c.commitStages(stages); err != nil {
+		return change{}, err
+	}
+
+	return c.publish(settings), nil
+}
+
+// stagedProviders returns the provider each staged layer will read from once stages are
+// committed, keyed by the provider it reads from now. Stages that are written in place
+// (see inPlace) are written into a copy of the provider instead, in layer order, as
+// commitStages writes them into the provider itself. Assumes c.mu is held.
+func (c *Config) stagedProviders(stages map[contract.Layer]contract.Stage) (map[contract.Provider]contract.Provider, error) {
+	staged := make(map[contract.Provider]contract.Provider, len(stages))
+
+	for _, name := range defaultLayerOrder {
+		pending, ok := stages[name]
+		if !ok {
+			continue
+		}
+
+		current, _ := c.layer(name)
+		if !c.inPlace(name, current.provider) {
+			staged[current.provider] = pending.Provider()
+
+			continue
+		}
+
+		shared, ok := pending.(contract.SharedStage)
+		if !ok {
+			return nil, errors.ErrSharedProviderNotStageable
+		}
+
+		preview, ok := staged[current.provider]
+		if !ok {
+			cloner, canClone := current.provider.(contract.Cloner)
+			if !canClone {
+				return nil, errors.ErrSharedProviderNotStageable
+			}
+
+			clone, err := cloner.Clone()
+			if err != nil {
+				return nil, fmt.Errorf("error copying shared provider: %w", err)
+			}
+
+			preview, staged[current.provider] = clone, clone
+		}
+
+		if err := shared.ApplyTo(preview); err != nil {
+			return nil, fmt.Errorf("error staging %s: %w", name, err)
+		}
+	}
+
+	return staged, nil
+}
+
+// commitStages makes the loaders use their stages, in layer order. The stage of the
+// overrides layer, or of a loader whose provider is shared with another layer, is
+// written into that provider. Assumes c.mu is held and stagedProviders accepted stages.
+func (c *Config) commitStages(stages map[contract.Layer]contract.Stage) error {
+	for _, name := range defaultLayerOrder {
+		pending, ok := stages[name]
+		if !ok {
+			continue
+		}
+
+		current, _ := c.layer(name)
+		if !c.inPlace(name, current.provider) {
+			pending.Commit()
+
+			continue
+		}
+
+		if shared, ok := pending.(contract.SharedStage); ok {
+			if err := shared.CommitTo(current.provider); err != nil {
+				return fmt.Errorf("error committing %s: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// inPlace reports whether the stage of the named layer is written into provider instead
+// of replacing it: the overrides provider belongs to the caller, and a provider shared
+// with another layer cannot be swapped without losing that layer's values.
+// Assumes c.mu is held.
+func (c *Config) inPlace(name contract.Layer, provider contract.Provider) bool {
+	return name == contract.LayerOverrides || c.sharedProvider(provider)
+}
+
+// sharedProvider reports whether provider backs more than one layer.
+// Assumes c.mu is held.
+func (c *Config) sharedProvider(provider contract.Provider) bool {
+	count := 0
+
+	for _, name := range defaultLayerOrder {
+		if l, ok := c.layer(name); ok && l.provider == provider {
+			count++
+		}
+	}
+
+	return count > 1
+}
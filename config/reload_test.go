@@ -0,0 +1,185 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/loader/file"
+	"github.com/hbttundar/scg-config/provider/viper"
+	"github.com/hbttundar/scg-config/schema"
+)
+
+func TestConfig_ReloadKeepsLastKnownGood(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "app.yaml", "app:\n  name: v1\n")
+	writeFile(t, dir, "db.yaml", "db:\n  host: h1\n")
+
+	cfg := config.New()
+	require.NoError(t, cfg.FileLoader().LoadFromDirectory(dir))
+	assert.True(t, cfg.LastGoodAt().IsZero())
+	require.NoError(t, cfg.Reload())
+	require.NoError(t, cfg.LastReloadError())
+
+	goodAt := cfg.LastGoodAt()
+	assert.False(t, goodAt.IsZero())
+
+	// app.yaml parses, db.yaml does not: nothing from this edit may be applied.
+	writeFile(t, dir, "app.yaml", "app:\n  name: v2\n")
+	writeFile(t, dir, "db.yaml", "db: [h2\n")
+
+	err := cfg.Reload()
+	require.ErrorIs(t, err, errors.ErrParseConfigFileFailed)
+	require.ErrorIs(t, cfg.LastReloadError(), errors.ErrParseConfigFileFailed)
+	assert.Equal(t, goodAt, cfg.LastGoodAt())
+	assert.Equal(t, "v1", config.MustGet[string](cfg, "app.name"))
+
+	// Republishing from the providers must not reveal a half-applied reload either.
+	cfg.Set("unrelated", true)
+	assert.Equal(t, "v1", config.MustGet[string](cfg, "app.name"))
+	assert.Equal(t, "h1", config.MustGet[string](cfg, "db.host"))
+
+	writeFile(t, dir, "db.yaml", "db:\n  host: h2\n")
+	require.NoError(t, cfg.Reload())
+	require.NoError(t, cfg.LastReloadError())
+	assert.True(t, cfg.LastGoodAt().After(goodAt))
+	assert.Equal(t, "v2", config.MustGet[string](cfg, "app.name"))
+	assert.Equal(t, "h2", config.MustGet[string](cfg, "db.host"))
+}
+
+func TestConfig_RejectedReloadLeavesSourcesUntouched(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "port: 80\n")
+
+	portSchema := schema.New()
+	portSchema.Field("port").Max(1024)
+
+	cfg := config.New(config.WithSchema(portSchema))
+	require.NoError(t, cfg.FileLoader().LoadFromFile(path))
+	require.NoError(t, cfg.Reload())
+
+	writeFile(t, dir, "app.yaml", "port: 8080\n")
+	require.ErrorIs(t, cfg.Reload(), errors.ErrValidationFailed)
+	require.ErrorIs(t, cfg.LastReloadError(), errors.ErrValidationFailed)
+
+	cfg.SetDefault("timeout", "5s")
+	assert.Equal(t, 80, config.MustGet[int](cfg, "port"), "the rejected file must not leak in on the next publish")
+	assert.Equal(t, 80, cfg.FileLoader().GetProvider().GetKey("port"))
+}
+
+func TestConfig_ReadInConfigIsTransactional(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "a: 1\n")
+	writeFile(t, dir, "b.yaml", "b: 1\n")
+
+	cfg := config.New()
+	require.NoError(t, cfg.FileLoader().LoadFromDirectory(dir))
+	require.NoError(t, cfg.ReadInConfig())
+	require.NoError(t, cfg.Reload())
+
+	// a.yaml parses, b.yaml does not: the file loader must keep both old values.
+	writeFile(t, dir, "a.yaml", "a: 2\n")
+	writeFile(t, dir, "b.yaml", "b: [1\n")
+
+	require.ErrorIs(t, cfg.ReadInConfig(), errors.ErrParseConfigFileFailed)
+	assert.Equal(t, 1, cfg.FileLoader().GetProvider().GetKey("a"))
+	assert.Equal(t, 1, cfg.FileLoader().GetProvider().GetKey("b"))
+
+	cfg.Set("x", 1)
+	assert.Equal(t, 1, config.MustGet[int](cfg, "a"))
+	assert.Equal(t, 1, config.MustGet[int](cfg, "b"))
+
+	writeFile(t, dir, "b.yaml", "b: 2\n")
+	require.NoError(t, cfg.ReadInConfig())
+	assert.Equal(t, 2, cfg.FileLoader().GetProvider().GetKey("b"))
+}
+
+func TestConfig_SharedProviderReloadIsTransactional(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "a: 1\n")
+	writeFile(t, dir, "b.yaml", "b: 1\n")
+
+	aSchema := schema.New()
+	aSchema.Field("a").Max(10)
+
+	// The file loader shares the overrides provider, so it cannot be swapped on reload.
+	provider := viper.NewConfigProvider()
+	cfg := config.New(
+		config.WithProvider(provider),
+		config.WithFileLoader(file.NewFileLoader(provider)),
+		config.WithSchema(aSchema),
+	)
+	require.NoError(t, cfg.FileLoader().LoadFromDirectory(dir))
+	cfg.Set("o", 1)
+	require.NoError(t, cfg.Reload())
+
+	// a.yaml parses, b.yaml does not: nothing from this edit may be applied.
+	writeFile(t, dir, "a.yaml", "a: 2\n")
+	writeFile(t, dir, "b.yaml", "b: [1\n")
+	require.ErrorIs(t, cfg.Reload(), errors.ErrParseConfigFileFailed)
+
+	cfg.Set("x", 1)
+	assert.Equal(t, 1, config.MustGet[int](cfg, "a"))
+	assert.Equal(t, 1, config.MustGet[int](cfg, "b"))
+
+	// Both files parse but a fails validation: the shared provider must not change.
+	writeFile(t, dir, "a.yaml", "a: 20\n")
+	writeFile(t, dir, "b.yaml", "b: 2\n")
+	require.ErrorIs(t, cfg.Reload(), errors.ErrValidationFailed)
+
+	cfg.Set("x", 2)
+	assert.Equal(t, 1, config.MustGet[int](cfg, "a"))
+	assert.Equal(t, 1, config.MustGet[int](cfg, "b"))
+
+	writeFile(t, dir, "a.yaml", "a: 2\n")
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, 2, config.MustGet[int](cfg, "a"))
+	assert.Equal(t, 2, config.MustGet[int](cfg, "b"))
+	assert.Equal(t, 1, config.MustGet[int](cfg, "o"), "values set with Set survive a reload of the shared provider")
+}
+
+func TestConfig_ReloadRereadsProviderConfigFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "port: 80\nname: v1\n")
+
+	portSchema := schema.New()
+	portSchema.Field("port").Max(1024)
+
+	provider := viper.NewConfigProvider()
+	provider.SetConfigFile(path)
+	require.NoError(t, provider.ReadInConfig())
+
+	cfg := config.New(config.WithProvider(provider), config.WithSchema(portSchema))
+	cfg.Set("o", 1)
+
+	writeFile(t, dir, "app.yaml", "port: 81\nname: v2\n")
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, 81, config.MustGet[int](cfg, "port"))
+	assert.Equal(t, "v2", config.MustGet[string](cfg, "name"))
+	assert.Equal(t, 1, config.MustGet[int](cfg, "o"), "values set with Set survive re-reading the file")
+
+	// A broken or invalid file leaves the provider as it was.
+	writeFile(t, dir, "app.yaml", "port: [82\n")
+	require.ErrorIs(t, cfg.Reload(), errors.ErrParseConfigFileFailed)
+
+	writeFile(t, dir, "app.yaml", "port: 8080\nname: v3\n")
+	require.ErrorIs(t, cfg.ReadInConfig(), errors.ErrValidationFailed)
+	assert.Equal(t, 81, provider.GetKey("port"))
+
+	cfg.Set("x", 1)
+	assert.Equal(t, 81, config.MustGet[int](cfg, "port"))
+	assert.Equal(t, "v2", config.MustGet[string](cfg, "name"))
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/dotmap"
+)
+
+// Snapshot is an immutable view of the configuration at one point in time.
+// A request handler can hold on to one Snapshot to read a consistent config for its
+// whole lifetime, even while the Config is being reloaded.
+type Snapshot struct {
+	getter   *Getter
+	version  uint64
+	loadedAt time.Time
+}
+
+// newSnapshot freezes a deep copy of settings so later changes to it are not visible.
+func newSnapshot(settings map[string]any, version uint64) *Snapshot {
+	frozen, _ := copyValue(settings).(map[string]any)
+
+	return &Snapshot{
+		getter:   NewGetter(frozen),
+		version:  version,
+		loadedAt: time.Now(),
+	}
+}
+
+// Get returns the value at key converted to typ. Maps and slices are returned as copies.
+func (s *Snapshot) Get(key string, typ contract.KeyType) (any, error) {
+	val, err := s.getter.Get(key, typ)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyValue(val), nil
+}
+
+// Has reports whether key exists in the snapshot.
+func (s *Snapshot) Has(key string) bool {
+	return s.getter.HasKey(key)
+}
+
+// Lookup returns a copy of the raw value stored at key.
+func (s *Snapshot) Lookup(key string) (any, error) {
+	val, err := s.getter.Lookup(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyValue(val), nil
+}
+
+// Bind populates out from a copy of the subtree at prefix (see Getter.Bind).
+func (s *Snapshot) Bind(prefix string, out any) error {
+	return NewGetter(s.AllSettings()).Bind(prefix, out)
+}
+
+// AllSettings returns a copy of the whole configuration as a nested map.
+func (s *Snapshot) AllSettings() map[string]any {
+	settings, _ := copyValue(s.getter.config).(map[string]any)
+
+	return settings
+}
+
+// Diff returns what changed from s to other (see dotmap.Diff).
+func (s *Snapshot) Diff(other *Snapshot) []dotmap.Change {
+	return dotmap.Diff(s.getter.config, other.getter.config)
+}
+
+// Version increases by one every time the Config publishes a new snapshot.
+func (s *Snapshot) Version() uint64 {
+	return s.version
+}
+
+// LoadedAt is when the snapshot was built.
+func (s *Snapshot) LoadedAt() time.Time {
+	return s.loadedAt
+}
+
+// copyValue deep-copies maps and slices so that a snapshot never shares mutable state
+// with a provider or a caller. Other values are returned as they are.
+func copyValue(val any) any {
+	switch value := val.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(value))
+		for k, v := range value {
+			out[k] = copyValue(v)
+		}
+
+		return out
+	case []any:
+		out := make([]any, len(value))
+		for i, v := range value {
+			out[i] = copyValue(v)
+		}
+
+		return out
+	case nil:
+		return nil
+	}
+
+	return copyReflect(val)
+}
+
+// copyReflect copies the less common map and slice types, such as map[string]string,
+// map[any]any or []string.
+func copyReflect(val any) any {
+	src := reflect.ValueOf(val)
+
+	switch src.Kind() { //nolint:exhaustive // only maps and slices need copying
+	case reflect.Map:
+		if src.IsNil() {
+			return val
+		}
+
+		out := reflect.MakeMapWithSize(src.Type(), src.Len())
+
+		iter := src.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), copyElem(iter.Value(), src.Type().Elem()))
+		}
+
+		return out.Interface()
+	case reflect.Slice:
+		if src.IsNil() {
+			return val
+		}
+
+		out := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := range src.Len() {
+			out.Index(i).Set(copyElem(src.Index(i), src.Type().Elem()))
+		}
+
+		return out.Interface()
+	default:
+		return val
+	}
+}
+
+// copyElem deep-copies a map or slice element, keeping it assignable to elemType.
+func copyElem(elem reflect.Value, elemType reflect.Type) reflect.Value {
+	if !elem.IsValid() || (elem.Kind() == reflect.Interface && elem.IsNil()) {
+		return reflect.Zero(elemType)
+	}
+
+	copied := copyValue(elem.Interface())
+	if copied == nil {
+		return reflect.Zero(elemType)
+	}
+
+	return reflect.ValueOf(copied)
+}
+
+// Compile time checks for interface.
+var _ contract.ValueAccessor = (*Snapshot)(nil)
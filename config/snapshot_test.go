@@ -0,0 +1,109 @@
+package config_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/dotmap"
+)
+
+func TestConfig_SnapshotIsImmutable(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New()
+	cfg.Set("server", map[string]any{"port": 8080, "hosts": []any{"a", "b"}})
+
+	snap := cfg.Snapshot()
+	require.NotNil(t, snap)
+
+	hosts, err := snap.Lookup("server.hosts")
+	require.NoError(t, err)
+	hosts.([]any)[0] = "mutated"
+
+	server, err := snap.Get("server", contract.Map)
+	require.NoError(t, err)
+	server.(map[string]any)["port"] = 1
+
+	all := snap.AllSettings()
+	all["server"] = "gone"
+
+	assert.Equal(t, "a", config.MustGet[string](snap, "server.hosts.0"))
+	assert.Equal(t, 8080, config.MustGet[int](snap, "server.port"))
+
+	cfg.Set("server.port", 9090)
+	assert.Equal(t, 8080, config.MustGet[int](snap, "server.port"), "old snapshot must not change")
+	assert.Equal(t, 9090, config.MustGet[int](cfg, "server.port"))
+	assert.Greater(t, cfg.Snapshot().Version(), snap.Version())
+	assert.False(t, cfg.Snapshot().LoadedAt().Before(snap.LoadedAt()))
+}
+
+func TestConfig_ConcurrentReadsAndReloads(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "app:\n  name: v0\n  workers: 0\n")
+
+	cfg := config.New()
+	require.NoError(t, cfg.FileLoader().LoadFromFile(path))
+	require.NoError(t, cfg.Reload())
+
+	const iterations = 50
+
+	var wg sync.WaitGroup
+
+	for range 4 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range iterations {
+				snap := cfg.Snapshot()
+				name := config.MustGet[string](snap, "app.name")
+				workers := config.MustGet[int](snap, "app.workers")
+				// Both keys come from the same file write, so a snapshot must agree.
+				assert.Equal(t, fmt.Sprintf("v%d", workers), name)
+				assert.True(t, cfg.Has("app.name"))
+			}
+		}()
+	}
+
+	for i := 1; i <= iterations; i++ {
+		writeFile(t, dir, "app.yaml", fmt.Sprintf("app:\n  name: v%d\n  workers: %d\n", i, i))
+		require.NoError(t, cfg.Reload())
+	}
+
+	wg.Wait()
+	assert.Equal(t, "v50", config.MustGet[string](cfg, "app.name"))
+}
+
+func TestConfig_Diff(t *testing.T) {
+	t.Parallel()
+
+	staging := config.New(config.WithDefaults(map[string]any{
+		"db.host": "staging-db", "db.pool": 5, "debug": true,
+	}))
+	production := config.New(config.WithDefaults(map[string]any{
+		"db.host": "prod-db", "db.pool": 5, "replicas": 3,
+	}))
+
+	assert.Equal(t, []dotmap.Change{
+		{Path: "db.host", Kind: dotmap.Modified, Old: "staging-db", New: "prod-db"},
+		{Path: "debug", Kind: dotmap.Removed, Old: true, New: nil},
+		{Path: "replicas", Kind: dotmap.Added, Old: nil, New: 3},
+	}, staging.Diff(production))
+	assert.Empty(t, staging.Diff(staging))
+
+	previous := staging.Snapshot()
+	staging.Set("db.pool", 10)
+
+	assert.Equal(t, []dotmap.Change{
+		{Path: "db.pool", Kind: dotmap.Modified, Old: 5, New: 10},
+	}, previous.Diff(staging.Snapshot()))
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	errors2 "errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+)
+
+// Lookuper is implemented by anything that exposes raw config values by dotted key.
+// Both *Config and *Getter satisfy it, so the generic accessors work on either.
+type Lookuper interface {
+	Lookup(key string) (any, error)
+}
+
+// GetAs returns the value at key converted to T.
+// The conversion is inferred from T using the same converters as Get, and slices
+// and string-keyed maps of any supported element type are converted element-wise.
+func GetAs[T any](src Lookuper, key string) (T, error) {
+	var zero T
+
+	raw, err := src.Lookup(key)
+	if err != nil {
+		return zero, err
+	}
+
+	val, err := convertTo(raw, reflect.TypeFor[T](), key)
+	if err != nil {
+		return zero, err
+	}
+
+	result, ok := val.Interface().(T)
+	if !ok {
+		return zero, errors.ErrWrongType
+	}
+
+	return result, nil
+}
+
+// MustGet is like GetAs but panics if the key is missing or cannot be converted.
+func MustGet[T any](src Lookuper, key string) T {
+	val, err := GetAs[T](src, key)
+	if err != nil {
+		panic(err)
+	}
+
+	return val
+}
+
+// GetOr is like GetAs but returns def if the key is missing or cannot be converted.
+func GetOr[T any](src Lookuper, key string, def T) T {
+	val, err := GetAs[T](src, key)
+	if err != nil {
+		return def
+	}
+
+	return val
+}
+
+// convertError maps a conversion failure at path onto the config sentinel errors.
+func convertError(path string, err error) error {
+	if errors2.Is(err, errors.ErrUnknownType) {
+		return fmt.Errorf("%w: %s", errors.ErrUnknownType, path)
+	}
+
+	return fmt.Errorf("%w: %s: %w", errors.ErrWrongType, path, err)
+}
+
+// keyTypesByType maps the Go types produced by typeConverters back to their KeyType.
+//
+//nolint:gochecknoglobals // static lookup table, mirrors typeConverters
+var keyTypesByType = map[reflect.Type]contract.KeyType{
+	reflect.TypeFor[int]():            contract.Int,
+	reflect.TypeFor[int32]():          contract.Int32,
+	reflect.TypeFor[int64]():          contract.Int64,
+	reflect.TypeFor[uint]():           contract.Uint,
+	reflect.TypeFor[uint32]():         contract.Uint32,
+	reflect.TypeFor[uint64]():         contract.Uint64,
+	reflect.TypeFor[float32]():        contract.Float32,
+	reflect.TypeFor[float64]():        contract.Float64,
+	reflect.TypeFor[string]():         contract.String,
+	reflect.TypeFor[bool]():           contract.Bool,
+	reflect.TypeFor[[]string]():       contract.StringSlice,
+	reflect.TypeFor[map[string]any](): contract.Map,
+	reflect.TypeFor[time.Time]():      contract.Time,
+	reflect.TypeFor[time.Duration]():  contract.Duration,
+	reflect.TypeFor[[]byte]():         contract.Bytes,
+	reflect.TypeFor[uuid.UUID]():      contract.UUID,
+	reflect.TypeFor[*url.URL]():       contract.URL,
+}
+
+// keyTypesByKind is the fallback for named types (e.g. type Level string) whose
+// underlying kind has a converter.
+//
+//nolint:gochecknoglobals // static lookup table, mirrors typeConverters
+var keyTypesByKind = map[reflect.Kind]contract.KeyType{
+	reflect.Int:     contract.Int,
+	reflect.Int8:    contract.Int64,
+	reflect.Int16:   contract.Int64,
+	reflect.Int32:   contract.Int32,
+	reflect.Int64:   contract.Int64,
+	reflect.Uint:    contract.Uint,
+	reflect.Uint8:   contract.Uint64,
+	reflect.Uint16:  contract.Uint64,
+	reflect.Uint32:  contract.Uint32,
+	reflect.Uint64:  contract.Uint64,
+	reflect.Float32: contract.Float32,
+	reflect.Float64: contract.Float64,
+	reflect.String:  contract.String,
+	reflect.Bool:    contract.Bool,
+}
+
+// convertTo converts val to the target type, recursing into slices, maps, pointers
+// and structs. Errors carry the dotted path of the value that failed.
+func convertTo(val any, target reflect.Type, path string) (reflect.Value, error) {
+	if typ, ok := keyTypesByType[target]; ok {
+		converted, err := tryTypeCast(val, typ)
+		if err == nil {
+			return reflect.ValueOf(converted), nil
+		}
+
+		// []string and map[string]any have element-wise fallbacks below.
+		if target.Kind() != reflect.Slice && target.Kind() != reflect.Map {
+			return reflect.Value{}, convertError(path, err)
+		}
+	}
+
+	switch target.Kind() {
+	case reflect.Interface:
+		if val == nil {
+			return reflect.Zero(target), nil
+		}
+
+		if !reflect.TypeOf(val).AssignableTo(target) {
+			return reflect.Value{}, convertError(path, errors.ErrWrongType)
+		}
+
+		return reflect.ValueOf(val), nil
+	case reflect.Slice:
+		return convertSlice(val, target, path)
+	case reflect.Map:
+		return convertMap(val, target, path)
+	case reflect.Struct:
+		out := reflect.New(target).Elem()
+
+		return out, bindStruct(val, out, path)
+	case reflect.Pointer:
+		if val == nil {
+			return reflect.Zero(target), nil
+		}
+
+		elem, err := convertTo(val, target.Elem(), path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ptr := reflect.New(target.Elem())
+		ptr.Elem().Set(elem)
+
+		return ptr, nil
+	default:
+		return convertKind(val, target, path)
+	}
+}
+
+// convertKind converts val for named types whose underlying kind has a converter.
+func convertKind(val any, target reflect.Type, path string) (reflect.Value, error) {
+	typ, ok := keyTypesByKind[target.Kind()]
+	if !ok {
+		return reflect.Value{}, convertError(path, errors.ErrUnknownType)
+	}
+
+	converted, err := tryTypeCast(val, typ)
+	if err != nil {
+		return reflect.Value{}, convertError(path, err)
+	}
+
+	out := reflect.ValueOf(converted)
+	if overflows(out, target) {
+		return reflect.Value{}, convertError(path, fmt.Errorf("%w: out of %s range", typeConverters[typ].errorType, target))
+	}
+
+	return out.Convert(target), nil
+}
+
+// overflows reports whether an integer value does not fit the narrower target type.
+func overflows(val reflect.Value, target reflect.Type) bool {
+	probe := reflect.New(target).Elem()
+
+	switch val.Kind() { //nolint:exhaustive // only integer kinds can overflow on conversion
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return probe.CanInt() && probe.OverflowInt(val.Int())
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return probe.CanUint() && probe.OverflowUint(val.Uint())
+	default:
+		return false
+	}
+}
+
+// convertSlice converts any slice value element by element.
+func convertSlice(val any, target reflect.Type, path string) (reflect.Value, error) {
+	src := reflect.ValueOf(val)
+	if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+		return reflect.Value{}, convertError(path, errors.ErrNotSlice)
+	}
+
+	out := reflect.MakeSlice(target, src.Len(), src.Len())
+
+	for idx := range src.Len() {
+		elem, err := convertTo(src.Index(idx).Interface(), target.Elem(), joinPath(path, strconv.Itoa(idx)))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		out.Index(idx).Set(elem)
+	}
+
+	return out, nil
+}
+
+// convertMap converts any map with string keys value by value.
+func convertMap(val any, target reflect.Type, path string) (reflect.Value, error) {
+	if target.Key().Kind() != reflect.String {
+		return reflect.Value{}, convertError(path, errors.ErrUnknownType)
+	}
+
+	src := reflect.ValueOf(val)
+	if src.Kind() != reflect.Map {
+		return reflect.Value{}, convertError(path, errors.ErrNotMap)
+	}
+
+	out := reflect.MakeMapWithSize(target, src.Len())
+	iter := src.MapRange()
+
+	for iter.Next() {
+		key := fmt.Sprint(iter.Key().Interface())
+
+		elem, err := convertTo(iter.Value().Interface(), target.Elem(), joinPath(path, key))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		out.SetMapIndex(reflect.ValueOf(key).Convert(target.Key()), elem)
+	}
+
+	return out, nil
+}
+
+// joinPath appends a segment to a dotted path.
+func joinPath(path, segment string) string {
+	if path == "" {
+		return segment
+	}
+
+	return path + "." + segment
+}
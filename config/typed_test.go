@@ -0,0 +1,116 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/provider/viper"
+)
+
+type logLevel string
+
+func TestGetAs_Scalars(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewGetter(baseConfigMap())
+
+	port, err := config.GetAs[int](conf, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, 123, port)
+
+	name, err := config.GetAs[string](conf, "bar")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", name)
+
+	ratio, err := config.GetAs[float32](conf, "foo")
+	require.NoError(t, err)
+	assert.InDelta(t, float32(123), ratio, 0)
+
+	deep, err := config.GetAs[int64](conf, "nested.deep.val")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), deep)
+
+	timeout, err := config.GetAs[time.Duration](conf, "duration")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, timeout)
+
+	level, err := config.GetAs[logLevel](conf, "bar")
+	require.NoError(t, err)
+	assert.Equal(t, logLevel("abc"), level)
+}
+
+func TestGetAs_Composites(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewGetter(map[string]any{
+		"ports":   []any{80, "443"},
+		"names":   []any{"a", "b"},
+		"limits":  map[string]any{"read": 10, "write": "20"},
+		"labels":  map[string]string{"env": "prod"},
+		"groups":  map[string]any{"admins": []any{"root"}},
+		"anyList": []any{1, "two"},
+	})
+
+	ports, err := config.GetAs[[]int](conf, "ports")
+	require.NoError(t, err)
+	assert.Equal(t, []int{80, 443}, ports)
+
+	names, err := config.GetAs[[]string](conf, "names")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	limits, err := config.GetAs[map[string]int](conf, "limits")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"read": 10, "write": 20}, limits)
+
+	labels, err := config.GetAs[map[string]any](conf, "labels")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"env": "prod"}, labels)
+
+	groups, err := config.GetAs[map[string][]string](conf, "groups")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"admins": {"root"}}, groups)
+
+	anyList, err := config.GetAs[[]any](conf, "anyList")
+	require.NoError(t, err)
+	assert.Equal(t, []any{1, "two"}, anyList)
+}
+
+func TestGetAs_Errors(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewGetter(baseConfigMap())
+
+	_, err := config.GetAs[int](conf, "missing")
+	require.ErrorIs(t, err, errors.ErrKeyNotFound)
+
+	_, err = config.GetAs[int](conf, "bar")
+	require.ErrorIs(t, err, errors.ErrWrongType)
+	require.ErrorIs(t, err, errors.ErrNotInt)
+
+	_, err = config.GetAs[[]int](conf, "anyslice")
+	require.ErrorIs(t, err, errors.ErrWrongType)
+
+	_, err = config.GetAs[chan int](conf, "foo")
+	require.ErrorIs(t, err, errors.ErrUnknownType)
+}
+
+func TestMustGetAndGetOr(t *testing.T) {
+	t.Parallel()
+
+	prov := viper.NewConfigProvider()
+	prov.Set("server.port", 8080)
+	cfg := config.New(config.WithProvider(prov))
+
+	assert.Equal(t, 8080, config.MustGet[int](cfg, "server.port"))
+	assert.Panics(t, func() { config.MustGet[int](cfg, "server.missing") })
+
+	assert.Equal(t, 8080, config.GetOr(cfg, "server.port", 9090))
+	assert.Equal(t, 9090, config.GetOr(cfg, "server.missing", 9090))
+	assert.Equal(t, time.Second, config.GetOr(cfg, "server.port.bad", time.Second))
+}
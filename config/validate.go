@@ -0,0 +1,62 @@
+package config
+
+import (
+	errors2 "errors"
+	"fmt"
+	"slices"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+)
+
+// WithSchema registers a validator that every reload must satisfy.
+func WithSchema(v contract.Validator) Option {
+	return func(c *Config) { c.addValidator(v) }
+}
+
+// RegisterSchema registers a validator that every later reload must satisfy.
+// It does not re-validate the current configuration; call Validate for that.
+func (c *Config) RegisterSchema(v contract.Validator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.addValidator(v)
+}
+
+// addValidator registers v. Assumes c.mu is held. The validators are also published
+// through c.schemas for knownKeys and keyTypes, which run without c.mu.
+func (c *Config) addValidator(v contract.Validator) {
+	c.validators = append(c.validators, v)
+
+	schemas := slices.Clone(c.validators)
+	c.schemas.Store(&schemas)
+}
+
+// Validate checks the current configuration against every registered schema and
+// returns all violations joined into one error, or nil.
+func (c *Config) Validate() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.validate(c.Snapshot().getter.config)
+}
+
+// validate runs every validator against settings. Assumes c.mu is held.
+func (c *Config) validate(settings map[string]any) error {
+	var errs []error
+
+	for _, validator := range c.validators {
+		err := validator.Validate(settings)
+		if err == nil {
+			continue
+		}
+
+		if !errors2.Is(err, errors.ErrValidationFailed) {
+			err = fmt.Errorf("%w: %w", errors.ErrValidationFailed, err)
+		}
+
+		errs = append(errs, err)
+	}
+
+	return errors2.Join(errs...)
+}
@@ -0,0 +1,49 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/schema"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	portSchema := schema.New()
+	portSchema.Field("server.port").Required().Type(contract.Int).Max(65535)
+
+	cfg := config.New(config.WithSchema(portSchema))
+	require.ErrorIs(t, cfg.Validate(), errors.ErrValidationFailed)
+
+	cfg.Set("server.port", 8080)
+	require.NoError(t, cfg.Validate())
+}
+
+func TestConfig_ReloadRejectsInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "server:\n  port: 8080\n")
+
+	cfg := config.New()
+	require.NoError(t, cfg.FileLoader().LoadFromFile(path))
+
+	portSchema := schema.New()
+	portSchema.Field("server.port").Required().Max(65535)
+	cfg.RegisterSchema(portSchema)
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, 8080, config.MustGet[int](cfg, "server.port"))
+
+	writeFile(t, dir, "app.yaml", "server:\n  port: 99999\n")
+
+	err := cfg.Reload()
+	require.ErrorIs(t, err, errors.ErrValidationFailed)
+	assert.Contains(t, err.Error(), "server.port: must be <= 65535")
+	assert.Equal(t, 8080, config.MustGet[int](cfg, "server.port"), "rejected reload must keep serving old values")
+}
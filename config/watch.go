@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Watch calls fn whenever the value at key changes, with the value before and after the
+// change (nil when the key is missing). key is a dotted path; a trailing ".*", as in
+// "database.*", is accepted for readability and watches the whole subtree, which is
+// also what a plain key pointing at a map does. fn runs like an OnChange listener.
+func (c *Config) Watch(key string, fn func(previous, current any)) {
+	path := watchPath(key)
+
+	c.OnChange(func(previous, current *Snapshot, changedKeys []string) {
+		if !pathChanged(path, changedKeys) {
+			return
+		}
+
+		fn(lookupOrNil(previous, path), lookupOrNil(current, path))
+	})
+}
+
+// WatchAs is Watch with both values converted to T as by GetAs. fn is only called when
+// the converted values differ, so a port changing from "80" to 80 is not reported.
+// A value that is missing or cannot be converted is passed as the zero value of T.
+func WatchAs[T any](c *Config, key string, fn func(previous, current T)) {
+	path := watchPath(key)
+
+	c.OnChange(func(previous, current *Snapshot, changedKeys []string) {
+		if !pathChanged(path, changedKeys) {
+			return
+		}
+
+		before, _ := GetAs[T](previous, path)
+		after, _ := GetAs[T](current, path)
+
+		if reflect.DeepEqual(before, after) {
+			return
+		}
+
+		fn(before, after)
+	})
+}
+
+// watchPath normalises a Watch key to the dotted path it refers to.
+func watchPath(key string) string {
+	return strings.TrimSuffix(strings.ToLower(key), ".*")
+}
+
+// pathChanged reports whether any changed leaf key lies at, below or above path.
+func pathChanged(path string, changedKeys []string) bool {
+	for _, changed := range changedKeys {
+		if changed == path || strings.HasPrefix(changed, path+".") || strings.HasPrefix(path, changed+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookupOrNil returns the value at path in snapshot, or nil when it is missing.
+func lookupOrNil(snapshot *Snapshot, path string) any {
+	val, err := snapshot.Lookup(path)
+	if err != nil {
+		return nil
+	}
+
+	return val
+}
@@ -0,0 +1,77 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+)
+
+func TestConfig_Watch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "app.yaml", "database:\n  host: db1\n  pool:\n    size: 10\napp:\n  name: one\n")
+
+	cfg := config.New()
+	require.NoError(t, cfg.FileLoader().LoadFromFile(path))
+	require.NoError(t, cfg.Reload())
+
+	var sizes [][2]any
+
+	cfg.Watch("database.pool.size", func(previous, current any) {
+		sizes = append(sizes, [2]any{previous, current})
+	})
+
+	var subtree []map[string]any
+
+	cfg.Watch("database.*", func(_, current any) {
+		settings, _ := current.(map[string]any)
+		subtree = append(subtree, settings)
+	})
+
+	// Only the app name changes: neither watcher fires.
+	writeFile(t, dir, "app.yaml", "database:\n  host: db1\n  pool:\n    size: 10\napp:\n  name: two\n")
+	require.NoError(t, cfg.Reload())
+	assert.Empty(t, sizes)
+	assert.Empty(t, subtree)
+
+	// The host changes: only the prefix watcher fires.
+	writeFile(t, dir, "app.yaml", "database:\n  host: db2\n  pool:\n    size: 10\napp:\n  name: two\n")
+	require.NoError(t, cfg.Reload())
+	assert.Empty(t, sizes)
+	require.Len(t, subtree, 1)
+	assert.Equal(t, "db2", subtree[0]["host"])
+
+	writeFile(t, dir, "app.yaml", "database:\n  host: db2\n  pool:\n    size: 20\napp:\n  name: two\n")
+	require.NoError(t, cfg.Reload())
+	require.Len(t, sizes, 1)
+	assert.Equal(t, [2]any{10, 20}, sizes[0])
+	assert.Len(t, subtree, 2)
+
+	// Removing the key reports nil as the new value.
+	writeFile(t, dir, "app.yaml", "app:\n  name: two\n")
+	require.NoError(t, cfg.Reload())
+	require.Len(t, sizes, 2)
+	assert.Equal(t, [2]any{20, nil}, sizes[1])
+}
+
+func TestWatchAs(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New(config.WithDefaults(map[string]any{"pool.size": "10"}))
+
+	var resized []int
+
+	config.WatchAs(cfg, "pool.size", func(previous, current int) {
+		resized = append(resized, previous, current)
+	})
+
+	cfg.Set("pool.size", 10)
+	assert.Empty(t, resized, "string 10 and int 10 convert to the same int")
+
+	cfg.Set("pool.size", 25)
+	assert.Equal(t, []int{10, 25}, resized)
+}
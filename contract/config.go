@@ -2,9 +2,14 @@ package contract
 
 // File extensions for supported config formats.
 const (
-	ExtYAML = ".yaml"
-	ExtYML  = ".yml"
-	ExtJSON = ".json"
+	ExtYAML       = ".yaml"
+	ExtYML        = ".yml"
+	ExtJSON       = ".json"
+	ExtTOML       = ".toml"
+	ExtHCL        = ".hcl"
+	ExtINI        = ".ini"
+	ExtProperties = ".properties"
+	ExtEnv        = ".env"
 )
 
 // KeyType describes supported type names for config keys.
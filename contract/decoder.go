@@ -0,0 +1,10 @@
+package contract
+
+// Decoder parses the contents of a config file into nested settings.
+type Decoder interface {
+	// Decode parses data, the whole contents of one file.
+	Decode(data []byte) (map[string]any, error)
+
+	// Extensions lists the file extensions the decoder handles, such as ".json5".
+	Extensions() []string
+}
@@ -0,0 +1,11 @@
+package contract
+
+// EnvSource provides environment variables, such as the process environment or a
+// fixed set used in tests.
+type EnvSource interface {
+	// Environ returns every variable as "NAME=value", like os.Environ.
+	Environ() []string
+
+	// LookupEnv returns the value of name and whether it is set, like os.LookupEnv.
+	LookupEnv(name string) (string, bool)
+}
@@ -0,0 +1,37 @@
+package contract
+
+// Layer names a source of configuration values. Values from higher-precedence
+// layers shadow values from lower ones.
+type Layer string
+
+const (
+	// LayerDefaults holds values registered in code or via struct tags.
+	LayerDefaults Layer = "defaults"
+	// LayerFiles holds values loaded by the FileLoader.
+	LayerFiles Layer = "files"
+	// LayerEnv holds values loaded by the EnvLoader.
+	LayerEnv Layer = "env"
+	// LayerFlags holds values taken from command-line flags.
+	LayerFlags Layer = "flags"
+	// LayerOverrides holds values set at runtime on the main provider.
+	LayerOverrides Layer = "overrides"
+)
+
+// Origin describes where a single configuration value was loaded from.
+type Origin struct {
+	Layer  Layer
+	File   string // path of the file that set the value, for LayerFiles and _FILE secrets
+	Line   int    // 1-based line in File, when the format reports positions
+	EnvVar string // environment variable name, for LayerEnv
+	Flag   string // command-line flag name, for LayerFlags
+
+	// Profile is the profile overlay that set the value, e.g. "staging" for
+	// app.staging.yaml. It is empty for base files and other layers.
+	Profile string
+}
+
+// OriginTracker is implemented by loaders that record where each key came from.
+type OriginTracker interface {
+	// Origin returns the origin of a dotted key, if the loader set it.
+	Origin(key string) (Origin, bool)
+}
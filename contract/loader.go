@@ -10,3 +10,63 @@ type FileLoader interface {
 	LoadFromDirectory(dir string) error
 	GetProvider() Provider
 }
+
+// ProfileLoader is implemented by file loaders that can load a directory of base files
+// together with the overlays of one or more profiles. StageProfile reads the same files
+// as LoadProfile, together with every source loaded so far, without touching the
+// loader until the stage is committed.
+type ProfileLoader interface {
+	LoadProfile(dir string, profiles ...string) error
+	StageProfile(dir string, profiles ...string) (Stage, error)
+}
+
+// DirectoryLister is implemented by file loaders that can list the config files they
+// load from a directory, and the directories they look in to find them.
+type DirectoryLister interface {
+	ListDirectory(dir string) (files, dirs []string, err error)
+}
+
+// EnvBinder is implemented by env loaders that can feed a key from specific
+// variables, independent of the prefix. StageBind reads the environment with the
+// binding added without touching the loader until the stage is committed.
+type EnvBinder interface {
+	Bind(key string, envNames ...string) error
+	StageBind(key string, envNames ...string) (Stage, error)
+}
+
+// Reloader is implemented by loaders that remember their sources and can re-read them.
+type Reloader interface {
+	Reload() error
+}
+
+// StagedReloader is implemented by loaders that can re-read their sources without
+// touching their current state, so that a reload that fails half-way changes nothing.
+type StagedReloader interface {
+	StageReload() (Stage, error)
+}
+
+// Stage holds freshly re-read sources until they are committed.
+type Stage interface {
+	OriginTracker
+
+	// Provider holds the freshly read values.
+	Provider() Provider
+
+	// Commit makes the loader use the staged provider and origins.
+	Commit()
+}
+
+// SharedStage is implemented by stages that can also be committed into a provider the
+// loader shares with another layer, which cannot be swapped for the staged provider
+// without losing that layer's values.
+type SharedStage interface {
+	Stage
+
+	// ApplyTo writes the staged values into provider as CommitTo does, without touching
+	// the loader.
+	ApplyTo(provider Provider) error
+
+	// CommitTo writes the staged values into provider and makes the loader use it with
+	// the staged origins.
+	CommitTo(provider Provider) error
+}
@@ -23,3 +23,9 @@ type Provider interface {
 	SetConfigFile(file string)
 	MergeConfigMap(cfg map[string]interface{}) error
 }
+
+// Cloner is implemented by providers that can copy themselves, values set with Set
+// included, so that a change can be tried on the copy before it is made.
+type Cloner interface {
+	Clone() (Provider, error)
+}
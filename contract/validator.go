@@ -0,0 +1,19 @@
+package contract
+
+// Validator checks a complete, merged configuration before it is served.
+type Validator interface {
+	// Validate returns an error describing every problem found in settings, or nil.
+	Validate(settings map[string]any) error
+}
+
+// KeyLister is implemented by validators that declare the keys they expect, such as
+// schemas, so that loaders can match sources like environment variables against them.
+type KeyLister interface {
+	Keys() []string
+}
+
+// KeyTyper is implemented by validators that declare the type of some keys, so that
+// loaders can convert raw values such as environment variables to it.
+type KeyTyper interface {
+	KeyTypes() map[string]KeyType
+}
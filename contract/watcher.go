@@ -3,5 +3,23 @@ package contract
 type Watcher interface {
 	AddFile(path string, callback func()) error
 	Watch(callback func())
+	OnError(handler func(error))
 	Close() error
 }
+
+// DirectoryWatcher is implemented by watchers that can watch every config file of a
+// directory, including files added to it later.
+type DirectoryWatcher interface {
+	AddDirectory(dir string, callback func()) error
+}
+
+// DirectoryListing returns the config files that belong to a watched directory and the
+// directories that must be watched to notice files being added to it.
+type DirectoryListing func() (files, dirs []string, err error)
+
+// ListingWatcher is implemented by watchers that can watch a directory laid out the way
+// a file loader reads it, such as recursively or with excluded files. The listing is
+// taken again whenever one of its directories changes.
+type ListingWatcher interface {
+	AddListing(dir string, listing DirectoryListing, callback func()) error
+}
@@ -0,0 +1,83 @@
+package decoder
+
+import (
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/internal/envkey"
+	"github.com/hbttundar/scg-config/provider/viper"
+)
+
+// viperDecoder decodes one of the formats Viper supports out of the box.
+type viperDecoder struct {
+	configType string
+	extensions []string
+	post       func(map[string]any) map[string]any
+}
+
+func (d viperDecoder) Decode(data []byte) (map[string]any, error) {
+	settings, err := viper.Decode(d.configType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.post != nil {
+		settings = d.post(settings)
+	}
+
+	return settings, nil
+}
+
+func (d viperDecoder) Extensions() []string {
+	return d.extensions
+}
+
+// builtins returns the decoders registered before any call to Register.
+func builtins() map[string]contract.Decoder {
+	decoders := make(map[string]contract.Decoder)
+
+	for _, d := range []viperDecoder{
+		{configType: "yaml", extensions: []string{contract.ExtYAML, contract.ExtYML}, post: nil},
+		{configType: "json", extensions: []string{contract.ExtJSON}, post: nil},
+		{configType: "toml", extensions: []string{contract.ExtTOML}, post: nil},
+		{configType: "hcl", extensions: []string{contract.ExtHCL}, post: unwrapHCLBlocks},
+		{configType: "ini", extensions: []string{contract.ExtINI}, post: nil},
+		{configType: "properties", extensions: []string{contract.ExtProperties}, post: nil},
+		{configType: "dotenv", extensions: []string{contract.ExtEnv}, post: nestEnvKeys},
+	} {
+		for _, ext := range d.extensions {
+			decoders[ext] = d
+		}
+	}
+
+	return decoders
+}
+
+// nestEnvKeys maps dotenv variable names to keys the way the env loader does, so that
+// APP_NAME=scg in a .env file sets app.name.
+func nestEnvKeys(settings map[string]any) map[string]any {
+	nested := viper.NewConfigProvider()
+	for name, val := range settings {
+		nested.Set(envkey.Normalize(name), val)
+	}
+
+	return nested.AllSettings()
+}
+
+// unwrapHCLBlocks replaces the single-element lists the HCL decoder produces for blocks
+// with the block itself, so that `app { port = 8080 }` is reachable as app.port.
+func unwrapHCLBlocks(settings map[string]any) map[string]any {
+	for key, val := range settings {
+		if blocks, ok := val.([]map[string]any); ok && len(blocks) == 1 {
+			settings[key] = blocks[0]
+			val = blocks[0]
+		}
+
+		if nested, ok := val.(map[string]any); ok {
+			unwrapHCLBlocks(nested)
+		}
+	}
+
+	return settings
+}
+
+// Compile time checks for interface.
+var _ contract.Decoder = (*viperDecoder)(nil)
@@ -0,0 +1,57 @@
+// Package decoder keeps the registry of config file formats the file loader and the
+// watcher understand, keyed by file extension.
+package decoder
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hbttundar/scg-config/contract"
+)
+
+//nolint:gochecknoglobals // process-wide registry, like image.RegisterFormat
+var registry = struct {
+	decoders map[string]contract.Decoder
+	mu       sync.RWMutex
+}{
+	decoders: builtins(),
+	mu:       sync.RWMutex{},
+}
+
+// Register makes d the decoder for each of its extensions, replacing any decoder,
+// built-in or not, registered for the same extension before. Extensions are matched
+// case-insensitively, and the leading dot is optional.
+func Register(d contract.Decoder) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for _, ext := range d.Extensions() {
+		if ext = normalizeExt(ext); ext != "." {
+			registry.decoders[ext] = d
+		}
+	}
+}
+
+// For returns the decoder registered for filename's extension.
+//
+//nolint:ireturn // decoders are only known by their interface
+func For(filename string) (contract.Decoder, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	d, ok := registry.decoders[normalizeExt(filepath.Ext(filename))]
+
+	return d, ok
+}
+
+// Supports reports whether a decoder is registered for filename's extension.
+func Supports(filename string) bool {
+	_, ok := For(filename)
+
+	return ok
+}
+
+func normalizeExt(ext string) string {
+	return "." + strings.ToLower(strings.TrimPrefix(ext, "."))
+}
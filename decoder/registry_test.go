@@ -0,0 +1,86 @@
+package decoder_test
+
+import (
+	"testing"
+
+	"github.com/hbttundar/scg-config/decoder"
+	"github.com/hbttundar/scg-config/utils"
+)
+
+type staticDecoder struct {
+	settings map[string]any
+	exts     []string
+}
+
+func (d staticDecoder) Decode([]byte) (map[string]any, error) { return d.settings, nil }
+func (d staticDecoder) Extensions() []string                  { return d.exts }
+
+func TestBuiltins(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"a.yaml", "a.yml", "a.json", "a.toml", "a.hcl", "a.ini", "a.properties", ".env", "A.YAML"} {
+		if !decoder.Supports(name) {
+			t.Errorf("Supports(%q) = false, want true", name)
+		}
+
+		if !utils.IsSupportedConfigFile(name) { //nolint:staticcheck // the deprecated shim must follow the registry
+			t.Errorf("utils.IsSupportedConfigFile(%q) = false, want true", name)
+		}
+	}
+
+	for _, name := range []string{"a.txt", "README", "a.yaml.bak"} {
+		if decoder.Supports(name) {
+			t.Errorf("Supports(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestBuiltins_Decode(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"a.yaml": "app:\n  name: scg\n",
+		"a.hcl":  "app {\n  name = \"scg\"\n}\n",
+		".env":   "APP_NAME=scg\n",
+	}
+
+	for name, data := range cases {
+		dec, ok := decoder.For(name)
+		if !ok {
+			t.Fatalf("no decoder for %s", name)
+		}
+
+		settings, err := dec.Decode([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: Decode error: %v", name, err)
+		}
+
+		app, _ := settings["app"].(map[string]any)
+		if app["name"] != "scg" {
+			t.Errorf("%s: app.name = %#v, want \"scg\"", name, app["name"])
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	custom := staticDecoder{settings: map[string]any{"custom": true}, exts: []string{"regtest", ".RegTest2", ""}}
+	decoder.Register(custom)
+
+	for _, name := range []string{"a.regtest", "a.REGTEST", "a.regtest2"} {
+		dec, ok := decoder.For(name)
+		if !ok {
+			t.Fatalf("For(%q) found no decoder", name)
+		}
+
+		settings, _ := dec.Decode(nil)
+		if settings["custom"] != true {
+			t.Errorf("For(%q) returned the wrong decoder", name)
+		}
+	}
+
+	if decoder.Supports("no-extension") {
+		t.Error("an empty extension must not be registered")
+	}
+}
@@ -0,0 +1,131 @@
+package dotmap
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// ChangeKind says how a path differs between two maps.
+type ChangeKind string
+
+const (
+	Added    ChangeKind = "added"
+	Removed  ChangeKind = "removed"
+	Modified ChangeKind = "modified"
+)
+
+// Change is one difference found by Diff. Old is nil for Added and New is nil for Removed.
+type Change struct {
+	Path string
+	Kind ChangeKind
+	Old  interface{}
+	New  interface{}
+}
+
+// Diff returns the differences between a and b as dotted paths, in path order with
+// slice indexes in numeric order. Nested maps of any supported shape are compared key by
+// key and slices index by index (e.g. "servers.1.host"); any other values are compared
+// with reflect.DeepEqual. A value that changes between a map, a slice and a scalar is
+// reported once, as Modified, at the path where the shapes diverge.
+func Diff(a, b map[string]interface{}) []Change {
+	var changes []Change
+
+	diffMaps(&changes, "", a, b)
+
+	return changes
+}
+
+// diffValues compares two values found at the same path.
+func diffValues(changes *[]Change, path string, old, updated interface{}) {
+	oldMap, oldIsMap := mapEntries(old)
+	newMap, newIsMap := mapEntries(updated)
+
+	if oldIsMap && newIsMap {
+		diffMaps(changes, path, oldMap, newMap)
+
+		return
+	}
+
+	oldSlice, oldIsSlice := sliceEntries(old)
+	newSlice, newIsSlice := sliceEntries(updated)
+
+	if oldIsSlice && newIsSlice {
+		diffSlices(changes, path, oldSlice, newSlice)
+
+		return
+	}
+
+	if !reflect.DeepEqual(old, updated) {
+		*changes = append(*changes, Change{Path: path, Kind: Modified, Old: old, New: updated})
+	}
+}
+
+// diffMaps compares two maps key by key in sorted key order.
+func diffMaps(changes *[]Change, prefix string, old, updated map[string]interface{}) {
+	keys := slices.Collect(maps.Keys(old))
+	for key := range updated {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		path := joinPath(prefix, key)
+		oldVal, inOld := old[key]
+		newVal, inNew := updated[key]
+
+		switch {
+		case !inOld:
+			*changes = append(*changes, Change{Path: path, Kind: Added, Old: nil, New: newVal})
+		case !inNew:
+			*changes = append(*changes, Change{Path: path, Kind: Removed, Old: oldVal, New: nil})
+		default:
+			diffValues(changes, path, oldVal, newVal)
+		}
+	}
+}
+
+// diffSlices compares two slices index by index.
+func diffSlices(changes *[]Change, prefix string, old, updated []interface{}) {
+	for idx := range max(len(old), len(updated)) {
+		path := joinPath(prefix, strconv.Itoa(idx))
+
+		switch {
+		case idx >= len(old):
+			*changes = append(*changes, Change{Path: path, Kind: Added, Old: nil, New: updated[idx]})
+		case idx >= len(updated):
+			*changes = append(*changes, Change{Path: path, Kind: Removed, Old: old[idx], New: nil})
+		default:
+			diffValues(changes, path, old[idx], updated[idx])
+		}
+	}
+}
+
+// sliceEntries returns the elements of the slice shapes Resolve can index into.
+func sliceEntries(value interface{}) ([]interface{}, bool) {
+	switch curr := value.(type) {
+	case []interface{}:
+		return curr, true
+	case []string:
+		entries := make([]interface{}, len(curr))
+		for i, v := range curr {
+			entries[i] = v
+		}
+
+		return entries, true
+	}
+
+	return nil, false
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
@@ -0,0 +1,60 @@
+package dotmap_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hbttundar/scg-config/dotmap"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	before := map[string]interface{}{
+		"app": map[string]interface{}{
+			"name":  "scg",
+			"port":  8080,
+			"roles": []interface{}{"admin", "user"},
+		},
+		"labels":  map[string]string{"env": "staging", "team": "core"},
+		"iface":   map[interface{}]interface{}{"k": true},
+		"servers": []interface{}{map[string]interface{}{"host": "a"}},
+		"shape":   map[string]interface{}{"x": 1},
+		"legacy":  "gone",
+	}
+	after := map[string]interface{}{
+		"app": map[string]interface{}{
+			"name":  "scg",
+			"port":  9090,
+			"roles": []string{"admin"},
+			"debug": true,
+		},
+		"labels":  map[string]interface{}{"env": "prod", "team": "core"},
+		"iface":   map[interface{}]interface{}{"k": true},
+		"servers": []interface{}{map[string]interface{}{"host": "b"}, map[string]interface{}{"host": "c"}},
+		"shape":   "flat",
+	}
+
+	want := []dotmap.Change{
+		{Path: "app.debug", Kind: dotmap.Added, Old: nil, New: true},
+		{Path: "app.port", Kind: dotmap.Modified, Old: 8080, New: 9090},
+		{Path: "app.roles.1", Kind: dotmap.Removed, Old: "user", New: nil},
+		{Path: "labels.env", Kind: dotmap.Modified, Old: "staging", New: "prod"},
+		{Path: "legacy", Kind: dotmap.Removed, Old: "gone", New: nil},
+		{Path: "servers.0.host", Kind: dotmap.Modified, Old: "a", New: "b"},
+		{Path: "servers.1", Kind: dotmap.Added, Old: nil, New: map[string]interface{}{"host": "c"}},
+		{Path: "shape", Kind: dotmap.Modified, Old: map[string]interface{}{"x": 1}, New: "flat"},
+	}
+
+	if got := dotmap.Diff(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+
+	if got := dotmap.Diff(before, before); len(got) != 0 {
+		t.Errorf("Diff(x, x) = %v, want empty", got)
+	}
+
+	if got := dotmap.Diff(nil, map[string]interface{}{"a": 1}); len(got) != 1 || got[0].Kind != dotmap.Added {
+		t.Errorf("Diff(nil, {a: 1}) = %v, want one added change", got)
+	}
+}
@@ -125,3 +125,59 @@ func resolveInterfaceMap(configData map[interface{}]interface{}, key string, cas
 
 	return nil, false
 }
+
+// Flatten returns every leaf of a nested map keyed by its dotted path
+// (e.g. {"a": {"b": 1}} becomes {"a.b": 1}). Slices and empty maps are leaves.
+func Flatten(settings map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	flattenInto(flat, "", settings)
+
+	return flat
+}
+
+// flattenInto walks value and records its leaves in flat under prefix.
+func flattenInto(flat map[string]interface{}, prefix string, value interface{}) {
+	children, ok := mapEntries(value)
+	if !ok || len(children) == 0 {
+		if prefix != "" {
+			flat[prefix] = value
+		}
+
+		return
+	}
+
+	for key, child := range children {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		flattenInto(flat, path, child)
+	}
+}
+
+// mapEntries returns the entries of any of the supported map shapes with string keys.
+func mapEntries(value interface{}) (map[string]interface{}, bool) {
+	switch curr := value.(type) {
+	case map[string]interface{}:
+		return curr, true
+	case map[string]string:
+		entries := make(map[string]interface{}, len(curr))
+		for k, v := range curr {
+			entries[k] = v
+		}
+
+		return entries, true
+	case map[interface{}]interface{}:
+		entries := make(map[string]interface{}, len(curr))
+		for k, v := range curr {
+			if ks, ok := k.(string); ok {
+				entries[ks] = v
+			}
+		}
+
+		return entries, true
+	}
+
+	return nil, false
+}
@@ -78,3 +78,37 @@ func TestResolver(t *testing.T) {
 		})
 	}
 }
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+
+	settings := map[string]interface{}{
+		"app": map[string]interface{}{
+			"name":  "scg",
+			"roles": []interface{}{"admin", "user"},
+			"deep":  map[string]interface{}{"x": 1},
+		},
+		"labels": map[string]string{"env": "prod"},
+		"iface":  map[interface{}]interface{}{"k": true},
+		"empty":  map[string]interface{}{},
+		"top":    42,
+	}
+
+	want := map[string]interface{}{
+		"app.name":   "scg",
+		"app.roles":  []interface{}{"admin", "user"},
+		"app.deep.x": 1,
+		"labels.env": "prod",
+		"iface.k":    true,
+		"empty":      map[string]interface{}{},
+		"top":        42,
+	}
+
+	if got := dotmap.Flatten(settings); !reflect.DeepEqual(got, want) {
+		t.Errorf("Flatten() = %v, want %v", got, want)
+	}
+
+	if got := dotmap.Flatten(nil); len(got) != 0 {
+		t.Errorf("Flatten(nil) = %v, want empty", got)
+	}
+}
@@ -6,4 +6,11 @@ var (
 	ErrKeyNotFound = errors.New("config: key not found")
 	ErrWrongType   = errors.New("config: wrong type for key")
 	ErrUnknownType = errors.New("config: unknown type for key")
+
+	ErrInvalidBindTarget = errors.New("config: bind target must be a non-nil pointer")
+
+	ErrValidationFailed = errors.New("config: validation failed")
+	ErrInvalidSchema    = errors.New("config: invalid schema")
+
+	ErrSharedProviderNotStageable = errors.New("config: a provider shared by several layers cannot be staged")
 )
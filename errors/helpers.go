@@ -16,6 +16,7 @@ var (
 	ErrNotBool          = errors2.New("not a bool")
 	ErrNotStringInSlice = errors2.New("not a string in slice")
 	ErrNotStringSlice   = errors2.New("not a string slice")
+	ErrNotSlice         = errors2.New("not a slice")
 	ErrNotMap           = errors2.New("not a map")
 	ErrNotTime          = errors2.New("not a time.Time")
 	ErrNotDuration      = errors2.New("not a duration")
@@ -6,5 +6,12 @@ var (
 	ErrBackendProviderNotSet      = errors.New("no provider provider set for environment loader")
 	ErrBackendProviderHasNoConfig = errors.New("provider provider has no config provider set")
 	ErrReadConfigFileFailed       = errors.New("failed to read configuration file")
+	ErrParseConfigFileFailed      = errors.New("failed to parse configuration file")
 	ErrFailedReadDirectory        = errors.New("failed to read directory")
+	ErrInvalidGlobPattern         = errors.New("invalid glob pattern")
+	ErrProfilesNotSupported       = errors.New("file loader does not support profiles")
+	ErrEnvBindingNotSupported     = errors.New("env loader does not support bindings")
+	ErrSecretFileFailed           = errors.New("failed to read secret file")
+	ErrSecretFileTooLarge         = errors.New("secret file is too large")
+	ErrSecretConflict             = errors.New("both a variable and its _FILE variant are set")
 )
@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrWatchSetupFailed           = errors.New("watcher: failed to set up watch")
+	ErrWatchFailed                = errors.New("watcher: file system watch failed")
+	ErrWatchDirectoryNotSupported = errors.New("watcher does not support watching directories")
+)
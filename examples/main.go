@@ -3,11 +3,12 @@ package main
 import (
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/hbttundar/scg-config/config"
 	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/loader/env"
+	"github.com/hbttundar/scg-config/provider/viper"
 )
 
 // This example demonstrates how to use SCG Config to load configuration from
@@ -17,14 +18,22 @@ import (
 // watcher.  See the accompanying files under examples/config and .env for
 // the data loaded in this example.
 func main() {
-	// 1. Initialise the configuration service with default provider, loaders and watcher.
-	cfg := config.New()
+	// 1. Initialise the configuration service with the default provider, file loader
+	// and watcher.  The env loader reads a fixed set of variables instead of the
+	// process environment, which keeps the example self‑contained (see step 3).
+	envLoader := env.NewEnvLoader(viper.NewConfigProvider(), env.WithEnvMap(map[string]string{
+		"APP_APP_NAME":     "SuperApp",
+		"APP_AUTH_ENABLED": "true",
+		"APP_LOGLEVEL":     "debug",
+	}))
+	cfg := config.New(config.WithEnvLoader(envLoader))
 
 	// 2. Load all supported configuration files from the examples/config directory.
-	// Each file's base name becomes a top‑level namespace.  Supported file
-	// extensions include .yaml, .yml and .json.  In this repository the
-	// app.yaml and database.json files define values under "app", "server",
-	// "auth" and "database".
+	// Files are merged at the root in alphabetical order, so each file spells
+	// out its own top‑level keys: in this repository app.yaml defines "app",
+	// "server" and "auth", and database.json defines "database".  Create the
+	// loader with file.WithNamespaceByFilename() to nest every file under its
+	// base name instead.
 	if err := cfg.FileLoader().LoadFromDirectory("./examples/config"); err != nil {
 		log.Fatalf("failed to load config directory: %v", err)
 	}
@@ -32,14 +41,9 @@ func main() {
 	// 3. Load environment variables with the prefix APP_.  The prefix is stripped
 	// and the remaining part is normalised to dot notation.  For example,
 	// APP_APP_NAME becomes "app.name" and APP_AUTH_ENABLED becomes "auth.enabled".
-	// In this example we source environment values from a .env file; in a real
-	// application these would come from the process environment.  To keep this
-	// example self‑contained we set the environment variables explicitly.
-	// You can also use a package like github.com/joho/godotenv to load a
-	// .env file before calling EnvLoader().
-	_ = os.Setenv("APP_APP_NAME", "SuperApp")
-	_ = os.Setenv("APP_AUTH_ENABLED", "true")
-	_ = os.Setenv("APP_LOGLEVEL", "debug")
+	// In a real application these would come from the process environment, which
+	// the env loader reads when it is not given a source; here they come from the
+	// map passed to env.WithEnvMap in step 1, so nothing calls os.Setenv.
 	if err := cfg.EnvLoader().LoadFromEnv("APP"); err != nil {
 		log.Fatalf("failed to load environment variables: %v", err)
 	}
@@ -60,46 +64,25 @@ func main() {
 	}
 	name := nameAny.(string)
 
-	portAny, err := cfg.Get("server.port", contract.Int)
+	// The generic accessors infer the conversion from the type parameter, so
+	// no KeyType or type assertion is needed.  GetOr falls back to a default
+	// when the key is missing or cannot be converted.
+	port, err := config.GetAs[int](cfg, "server.port")
 	if err != nil {
 		log.Fatalf("failed to get server.port: %v", err)
 	}
-	port := portAny.(int)
 
-	timeoutAny, err := cfg.Get("server.timeout", contract.Duration)
-	var timeout time.Duration
-	if err == nil {
-		timeout = timeoutAny.(time.Duration)
-	}
+	timeout := config.GetOr[time.Duration](cfg, "server.timeout", 0)
 
 	fmt.Printf("App: %s\nPort: %d\nTimeout: %s\n", name, port, timeout)
 
 	// 6. Access nested values and slices.  Values under nested maps and lists
 	// are available via dot notation.  For example, auth.roles is read from
 	// the YAML config.
-	authEnabledAny, err := cfg.Get("auth.enabled", contract.Bool)
-	if err != nil {
-		log.Fatalf("failed to get auth.enabled: %v", err)
-	}
-	authEnabled := authEnabledAny.(bool)
-
-	rolesAny, err := cfg.Get("auth.roles", contract.StringSlice)
-	if err != nil {
-		log.Fatalf("failed to get auth.roles: %v", err)
-	}
-	roles := rolesAny.([]string)
-
-	dbHostAny, err := cfg.Get("database.host", contract.String)
-	if err != nil {
-		log.Fatalf("failed to get database.host: %v", err)
-	}
-	dbHost := dbHostAny.(string)
-
-	dbPortAny, err := cfg.Get("database.port", contract.Int)
-	if err != nil {
-		log.Fatalf("failed to get database.port: %v", err)
-	}
-	dbPort := dbPortAny.(int)
+	authEnabled := config.MustGet[bool](cfg, "auth.enabled")
+	roles := config.MustGet[[]string](cfg, "auth.roles")
+	dbHost := config.MustGet[string](cfg, "database.host")
+	dbPort := config.MustGet[int](cfg, "database.port")
 
 	fmt.Printf("Auth enabled: %v\nRoles: %v\nDB: %s:%d\n", authEnabled, roles, dbHost, dbPort)
 
@@ -111,35 +94,32 @@ func main() {
 		fmt.Println("Redis cache not configured.")
 	}
 
-	// 8. Programmatically override a value at runtime by writing to the
-	// underlying provider.  After setting a value, call Reload() to update
-	// the getter.  Here we change the server port to 9090.
-	cfg.Provider().Set("server.port", 9090)
-	if err := cfg.Reload(); err != nil {
-		log.Fatalf("failed to reload after override: %v", err)
-	}
-	newPortAny, err := cfg.Get("server.port", contract.Int)
-	if err == nil {
-		fmt.Printf("Server port overridden to: %d\n", newPortAny.(int))
+	// 8. Programmatically override a value at runtime.  Overrides form the
+	// highest-precedence layer, so they win over files, env and flags.  Explain
+	// shows where the winning value came from and which values it shadows.
+	cfg.Set("server.port", 9090)
+	fmt.Printf("Server port overridden to: %d\n", config.MustGet[int](cfg, "server.port"))
+
+	if explanation, err := cfg.Explain("server.port"); err == nil {
+		for _, shadowed := range explanation.Shadowed {
+			fmt.Printf("  shadowed %v from %s %s:%d\n", shadowed.Value, shadowed.Origin.Layer,
+				shadowed.Origin.File, shadowed.Origin.Line)
+		}
 	}
 
 	// 9. Demonstrate hot reloading by watching the YAML file.  When the file
-	// changes, the watcher executes the provided callback.  In the callback we
-	// call Reload() to refresh the getter and print the new app.name.
+	// changes, the config is reloaded and every OnChange listener is told which
+	// keys changed.
+	cfg.OnChange(func(_, current *config.Snapshot, changedKeys []string) {
+		fmt.Println("app.yaml changed; changed keys:", changedKeys)
+		if v, err := current.Get("app.name", contract.String); err == nil {
+			fmt.Println("Updated app.name:", v.(string))
+		}
+	})
 	configFile := "./examples/config/app.yaml"
 	if err := cfg.StartWatching(configFile); err != nil {
 		log.Fatalf("failed to start watcher: %v", err)
 	}
-	cfg.Watcher().Watch(func() {
-		fmt.Println("app.yaml changed; reloading configuration…")
-		if err := cfg.Reload(); err != nil {
-			log.Printf("reload error: %v", err)
-			return
-		}
-		if v, err := cfg.Get("app.name", contract.String); err == nil {
-			fmt.Println("Updated app.name:", v.(string))
-		}
-	})
 
 	// 10. Block forever to allow the watcher to run.  Use Ctrl+C to exit.
 	select {}
@@ -0,0 +1,11 @@
+// Package envkey maps environment variable names to config keys. It is shared by utils
+// and the decoder registry, which utils depends on.
+package envkey
+
+import "strings"
+
+// Normalize converts an environment variable name (e.g. APP_NAME) to dot notation
+// (e.g. app.name).
+func Normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", "."))
+}
@@ -0,0 +1,68 @@
+// Package stage provides the contract.Stage the loaders return from StageReload.
+package stage
+
+import (
+	"github.com/hbttundar/scg-config/contract"
+)
+
+// CommitFunc makes a loader serve the staged provider and origins.
+type CommitFunc func(provider contract.Provider, origins map[string]contract.Origin)
+
+// ApplyFunc writes the values of a staged provider into target.
+type ApplyFunc func(staged, target contract.Provider) error
+
+// Stage holds sources re-read into a fresh provider until they are committed.
+type Stage struct {
+	provider contract.Provider
+	origins  map[string]contract.Origin
+	commit   CommitFunc
+	apply    ApplyFunc
+}
+
+// New returns a stage for provider and origins, which commit hands to the loader. apply
+// writes them into a provider the loader shares with another layer instead.
+func New(provider contract.Provider, origins map[string]contract.Origin, commit CommitFunc, apply ApplyFunc) *Stage {
+	return &Stage{provider: provider, origins: origins, commit: commit, apply: apply}
+}
+
+// Provider returns the staged provider.
+//
+//nolint:ireturn // returning an interface is required by the contract API
+func (s *Stage) Provider() contract.Provider {
+	return s.provider
+}
+
+// Origin returns where the staged value of key came from.
+func (s *Stage) Origin(key string) (contract.Origin, bool) {
+	origin, ok := s.origins[key]
+
+	return origin, ok
+}
+
+// Commit makes the loader serve the staged provider and origins.
+func (s *Stage) Commit() {
+	s.commit(s.provider, s.origins)
+}
+
+// ApplyTo writes the staged values into target without touching the loader.
+func (s *Stage) ApplyTo(target contract.Provider) error {
+	return s.apply(s.provider, target)
+}
+
+// CommitTo writes the staged values into target and makes the loader serve target with
+// the staged origins.
+func (s *Stage) CommitTo(target contract.Provider) error {
+	if err := s.apply(s.provider, target); err != nil {
+		return err
+	}
+
+	s.commit(target, s.origins)
+
+	return nil
+}
+
+// Compile time checks for interfaces.
+var (
+	_ contract.Stage       = (*Stage)(nil)
+	_ contract.SharedStage = (*Stage)(nil)
+)
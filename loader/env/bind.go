@@ -0,0 +1,112 @@
+package env
+
+import (
+	errors2 "errors"
+	"slices"
+	"strings"
+
+	"github.com/hbttundar/scg-config/contract"
+	loaderErrors "github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/internal/stage"
+)
+
+// binding feeds a key from the first of envNames that is set.
+type binding struct {
+	key      string
+	envNames []string
+}
+
+// Bind feeds key from the first of envNames that is set, whatever prefixes the loader
+// reads, e.g. Bind("database.url", "DATABASE_URL", "PG_URL") or Bind("server.port",
+// "PORT") for variables a platform injects. Without envNames, key is read from its
+// upper-cased form with dots replaced by underscores (SERVER_PORT for server.port).
+// Bound variables are read right away and on every reload, after the prefixes, so they
+// win over a variable a prefix maps to the same key. Binding a key again replaces its
+// variables.
+func (l *Loader) Bind(key string, envNames ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.provider == nil {
+		return loaderErrors.ErrBackendProviderNotSet
+	}
+
+	bound := newBinding(key, envNames)
+	l.bindings = withBinding(l.bindings, bound)
+
+	return loadBindings(l.provider, l.origins, []binding{bound}, l.mapping())
+}
+
+// StageBind reads every prefix and binding, with key bound as Bind would bind it, into
+// a fresh provider. The loader is left as it is until the returned stage is committed.
+//
+//nolint:ireturn // returning an interface is required by the contract API
+func (l *Loader) StageBind(key string, envNames ...string) (contract.Stage, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.provider == nil {
+		return nil, loaderErrors.ErrBackendProviderNotSet
+	}
+
+	bindings := withBinding(slices.Clone(l.bindings), newBinding(key, envNames))
+
+	provider, origins, err := l.readLocked(bindings)
+	if err != nil {
+		return nil, err
+	}
+
+	return stage.New(provider, origins, func(provider contract.Provider, origins map[string]contract.Origin) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.provider, l.origins, l.bindings = provider, origins, bindings
+	}, applyEnv), nil
+}
+
+// newBinding binds key, lower-cased, to envNames or else to its default variable.
+func newBinding(key string, envNames []string) binding {
+	key = strings.ToLower(key)
+	if len(envNames) == 0 {
+		envNames = []string{strings.ToUpper(strings.ReplaceAll(key, ".", "_"))}
+	}
+
+	return binding{key: key, envNames: envNames}
+}
+
+// withBinding returns bindings with bound added, replacing an earlier binding of the
+// same key.
+func withBinding(bindings []binding, bound binding) []binding {
+	if idx := slices.IndexFunc(bindings, func(b binding) bool { return b.key == bound.key }); idx >= 0 {
+		bindings[idx] = bound
+
+		return bindings
+	}
+
+	return append(bindings, bound)
+}
+
+// loadBindings copies the first set variable of every binding into provider and
+// records its name in origins.
+func loadBindings(provider contract.Provider, origins map[string]contract.Origin, bindings []binding, m mapping) error {
+	var errs []error
+
+	for _, bound := range bindings {
+		for _, name := range bound.envNames {
+			raw, origin, ok, err := m.lookup(name)
+			if err != nil {
+				errs = append(errs, err)
+
+				break
+			}
+
+			if ok {
+				m.set(provider, origins, bound.key, raw, origin)
+
+				break
+			}
+		}
+	}
+
+	return errors2.Join(errs...)
+}
@@ -0,0 +1,93 @@
+package env
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/utils"
+)
+
+// coerce converts raw to typ. Lists and maps may be written as JSON ([1, 2] or
+// {"a": 1}); lists may also be comma-separated (a, b). A value that does not convert
+// is kept as the raw string, so that Get and schema validation report it as before.
+func coerce(raw string, typ contract.KeyType) any {
+	var (
+		value any
+		err   error
+	)
+
+	switch typ {
+	case contract.Int:
+		value, err = utils.ToInt(strings.TrimSpace(raw))
+	case contract.Int32:
+		value, err = utils.ToInt32(strings.TrimSpace(raw))
+	case contract.Int64:
+		value, err = utils.ToInt64(strings.TrimSpace(raw))
+	case contract.Uint:
+		value, err = utils.ToUint(strings.TrimSpace(raw))
+	case contract.Uint32:
+		value, err = utils.ToUint32(strings.TrimSpace(raw))
+	case contract.Uint64:
+		value, err = utils.ToUint64(strings.TrimSpace(raw))
+	case contract.Float32:
+		value, err = utils.ToFloat32(strings.TrimSpace(raw))
+	case contract.Float64:
+		value, err = utils.ToFloat64(strings.TrimSpace(raw))
+	case contract.Bool:
+		value, err = utils.ToBool(strings.TrimSpace(raw))
+	case contract.Duration:
+		value, err = utils.ToDuration(strings.TrimSpace(raw))
+	case contract.Time:
+		value, err = utils.ToTime(strings.TrimSpace(raw))
+	case contract.StringSlice:
+		return coerceList(raw)
+	case contract.Map:
+		return coerceMap(raw)
+	default:
+		return raw
+	}
+
+	if err != nil {
+		return raw
+	}
+
+	return value
+}
+
+// coerceList parses a JSON array, keeping the element types, or else splits raw at
+// commas. An empty value is an empty list.
+func coerceList(raw string) any {
+	trimmed := strings.TrimSpace(raw)
+	if strings.HasPrefix(trimmed, "[") {
+		var list []any
+		// JSON is valid YAML, and the YAML decoder types numbers like the file loader
+		if err := yaml.Unmarshal([]byte(trimmed), &list); err == nil {
+			return list
+		}
+
+		return raw
+	}
+
+	if trimmed == "" {
+		return []string{}
+	}
+
+	items := strings.Split(trimmed, ",")
+	for idx, item := range items {
+		items[idx] = strings.TrimSpace(item)
+	}
+
+	return items
+}
+
+// coerceMap parses a JSON object.
+func coerceMap(raw string) any {
+	var settings map[string]any
+	if err := yaml.Unmarshal([]byte(strings.TrimSpace(raw)), &settings); err != nil || settings == nil {
+		return raw
+	}
+
+	return settings
+}
@@ -0,0 +1,54 @@
+package env
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hbttundar/scg-config/utils"
+)
+
+// KeyMapper maps the name of an environment variable, with the loader's prefix already
+// stripped (DB__MAX_OPEN_CONNS for APP_DB__MAX_OPEN_CONNS), to a dotted config key.
+// Variables for which it returns false are skipped.
+type KeyMapper func(envName string) (key string, ok bool)
+
+// DefaultKeyMapper lower-cases the name and turns every underscore into a dot, so
+// DB_HOST becomes db.host. It cannot produce keys that contain an underscore; use
+// NestedKeyMapper or WithKeyMatching for those.
+func DefaultKeyMapper(envName string) (string, bool) {
+	return utils.NormalizeEnvKey(envName), envName != ""
+}
+
+// NestedKeyMapper returns a mapper that only nests at separator and keeps every other
+// underscore: with "__", DB__MAX_OPEN_CONNS becomes db.max_open_conns.
+func NestedKeyMapper(separator string) KeyMapper {
+	return func(envName string) (string, bool) {
+		if envName == "" {
+			return "", false
+		}
+
+		return strings.ToLower(strings.ReplaceAll(envName, separator, ".")), true
+	}
+}
+
+// matchKnownKey returns the known key that envName spells, using either "_" or "__"
+// between the key's segments. Matching ignores case. If several keys match, the first
+// in sorted order wins, so the result does not depend on the order keys are listed in.
+func matchKnownKey(envName string, keys []string) (string, bool) {
+	envName = strings.ToUpper(envName)
+
+	var matches []string
+
+	for _, key := range keys {
+		upper := strings.ToUpper(key)
+		if envName == strings.ReplaceAll(upper, ".", "_") || envName == strings.ReplaceAll(upper, ".", "__") {
+			matches = append(matches, strings.ToLower(key))
+		}
+	}
+
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	return slices.Min(matches), true
+}
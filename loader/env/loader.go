@@ -2,51 +2,341 @@
 package env
 
 import (
-	"os"
+	errors2 "errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/dotmap"
 	loaderErrors "github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/internal/stage"
+	"github.com/hbttundar/scg-config/provider/viper"
 	"github.com/hbttundar/scg-config/utils"
 )
 
 // Loader loads configuration from environment variables into the provider provider.
 type Loader struct {
-	provider contract.Provider
+	provider   contract.Provider
+	prefixes   []string
+	bindings   []binding
+	origins    map[string]contract.Origin
+	mapper     KeyMapper
+	matchKnown bool
+	knownKeys  func() []string
+	coerce     bool
+	keyTypes   func() map[string]contract.KeyType
+	source     contract.EnvSource
+	// maxSecretSize is the size limit for _FILE secrets, which are read when positive
+	maxSecretSize int64
+	secretKeys    []string
+	mu            sync.RWMutex
+}
+
+// Option is a functional option for configuring the Loader.
+type Option func(*Loader)
+
+// WithKeyMapper sets how variable names are turned into keys. The default is
+// DefaultKeyMapper.
+func WithKeyMapper(mapper KeyMapper) Option {
+	return func(l *Loader) { l.mapper = mapper }
+}
+
+// WithKeyMatching makes the loader first look for a known key that the variable name
+// spells, with "_" or "__" between segments and ignoring case, so APP_DB_MAX_OPEN_CONNS
+// sets db.max_open_conns when that key is known. Names that match no known key go
+// through the key mapper. A Config supplies the keys it knows (those of its defaults,
+// files and flags and the fields of its schemas, never those of the environment); a
+// standalone loader gets them from SetKnownKeys.
+func WithKeyMatching() Option {
+	return func(l *Loader) { l.matchKnown = true }
+}
+
+// WithTypeCoercion converts every value to the type of the key it sets, instead of
+// storing it as a string: the type a Config's schema declares for the key, or else the
+// type of the value the key holds in the Config's defaults or files. So PORT=8080
+// overriding port: 80 is stored as the int 8080. Lists and maps may be written as JSON
+// (["a","b"], {"a":1}), and lists also as comma-separated values (a,b). Values that do
+// not convert are stored as strings. A standalone loader gets the types from
+// SetKeyTypes.
+func WithTypeCoercion() Option {
+	return func(l *Loader) { l.coerce = true }
 }
 
 // NewEnvLoader creates a new Loader for the given provider provider.
-func NewEnvLoader(p contract.Provider) *Loader {
-	return &Loader{provider: p}
+func NewEnvLoader(p contract.Provider, opts ...Option) *Loader {
+	loader := &Loader{
+		provider:   p,
+		prefixes:   nil,
+		bindings:   nil,
+		origins:    make(map[string]contract.Origin),
+		mapper:     DefaultKeyMapper,
+		matchKnown: false,
+		knownKeys:  nil,
+		coerce:     false,
+		keyTypes:   nil,
+		source:     ProcessSource{},
+		// _FILE variables are plain variables unless WithFileSecrets is given
+		maxSecretSize: 0,
+		secretKeys:    nil,
+		mu:            sync.RWMutex{},
+	}
+	for _, opt := range opts {
+		opt(loader)
+	}
+
+	return loader
+}
+
+// SetKnownKeys sets where WithKeyMatching gets the known keys from. It is called each
+// time the environment is read, so keys that appear later are matched on reload.
+func (l *Loader) SetKnownKeys(known func() []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.knownKeys = known
+}
+
+// SetKeyTypes sets where WithTypeCoercion gets the key types from. Like SetKnownKeys,
+// it is called each time the environment is read.
+func (l *Loader) SetKeyTypes(types func() map[string]contract.KeyType) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.keyTypes = types
 }
 
 // LoadFromEnv loads environment variables with the given prefix into the provider.
-// Prefix is stripped and keys are normalized to dot notation (e.g. APP_NAME -> app.name).
+// Prefix is stripped and keys are mapped to dot notation by the key mapper (e.g.
+// APP_NAME -> app.name).
 func (l *Loader) LoadFromEnv(prefix string) error {
-	provider := l.provider
-	if provider == nil {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.provider == nil {
+		return loaderErrors.ErrBackendProviderNotSet
+	}
+
+	m := l.mapping()
+	err := errors2.Join(
+		loadEnv(l.provider, l.origins, prefix, m),
+		// Explicit bindings win over variables found through a prefix
+		loadBindings(l.provider, l.origins, l.bindings, m),
+	)
+
+	if !slices.Contains(l.prefixes, prefix) {
+		l.prefixes = append(l.prefixes, prefix)
+	}
+
+	return err
+}
+
+// Reload reads the environment again for every prefix loaded so far and, once every
+// variable has been read, sets them in the provider, so a secret file that cannot be
+// read leaves the provider as it was.
+func (l *Loader) Reload() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.provider == nil {
 		return loaderErrors.ErrBackendProviderNotSet
 	}
 
+	provider, origins, err := l.readLocked(l.bindings)
+	if err != nil {
+		return err
+	}
+
+	if err := applyEnv(provider, l.provider); err != nil {
+		return err
+	}
+
+	maps.Copy(l.origins, origins)
+
+	return nil
+}
+
+// StageReload reads the environment again for every prefix into a fresh provider and
+// leaves the loader as it is until the returned stage is committed. Unlike Reload,
+// variables that have been unset since are dropped.
+//
+//nolint:ireturn // returning an interface is required by the contract API
+func (l *Loader) StageReload() (contract.Stage, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	provider, origins, err := l.readLocked(l.bindings)
+	if err != nil {
+		return nil, err
+	}
+
+	return stage.New(provider, origins, l.commit, applyEnv), nil
+}
+
+// readLocked reads every prefix loaded so far and then bindings into a fresh provider.
+// Assumes l.mu is held.
+func (l *Loader) readLocked(bindings []binding) (contract.Provider, map[string]contract.Origin, error) {
+	provider, origins := viper.NewConfigProvider(), make(map[string]contract.Origin)
+	m := l.mapping()
+
+	var errs []error
+	for _, prefix := range l.prefixes {
+		errs = append(errs, loadEnv(provider, origins, prefix, m))
+	}
+
+	errs = append(errs, loadBindings(provider, origins, bindings, m))
+	if err := errors2.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+
+	return provider, origins, nil
+}
+
+// commit makes the loader serve a staged provider and origins.
+func (l *Loader) commit(provider contract.Provider, origins map[string]contract.Origin) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.provider = provider
+	l.origins = origins
+}
+
+// applyEnv sets every value read into staged in target, on top of what target holds.
+func applyEnv(staged, target contract.Provider) error {
+	for key, value := range dotmap.Flatten(staged.AllSettings()) {
+		target.Set(key, value)
+	}
+
+	return nil
+}
+
+// Origin returns the environment variable that set key, if it was loaded by this loader.
+func (l *Loader) Origin(key string) (contract.Origin, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	origin, ok := l.origins[key]
+
+	return origin, ok
+}
+
+// mapping says where variables are read from and how they become keys and values.
+type mapping struct {
+	source        contract.EnvSource
+	mapKey        KeyMapper
+	types         map[string]contract.KeyType // nil unless coercing
+	maxSecretSize int64                       // zero unless reading _FILE secrets
+	secretKeys    []string                    // keys read from _FILE variables
+}
+
+// mapping returns how to map variables as the loader is configured: known keys are
+// matched first when created WithKeyMatching, and values are converted when created
+// WithTypeCoercion. The known keys and types are fetched once per call. Assumes l.mu
+// is held.
+func (l *Loader) mapping() mapping {
+	result := mapping{
+		source:        l.source,
+		mapKey:        l.mapper,
+		types:         nil,
+		maxSecretSize: l.maxSecretSize,
+		secretKeys:    l.secretKeys,
+	}
+
+	if l.matchKnown && l.knownKeys != nil {
+		keys, mapper := l.knownKeys(), l.mapper
+		result.mapKey = func(envName string) (string, bool) {
+			if key, ok := matchKnownKey(envName, keys); ok {
+				return key, true
+			}
+
+			return mapper(envName)
+		}
+	}
+
+	if l.coerce && l.keyTypes != nil {
+		result.types = make(map[string]contract.KeyType)
+		for key, typ := range l.keyTypes() {
+			result.types[strings.ToLower(key)] = typ
+		}
+	}
+
+	return result
+}
+
+// loadEnv copies matching environment variables into provider as m says, and records
+// their names in origins. A secret file that cannot be read is reported and skipped;
+// the other variables are still loaded.
+func loadEnv(provider contract.Provider, origins map[string]contract.Origin, prefix string, m mapping) error {
 	prefix = utils.NormalizePrefix(prefix)
 
-	for _, envStr := range os.Environ() {
+	var errs []error
+
+	for _, envStr := range m.source.Environ() {
 		if !utils.ShouldProcessEnv(envStr, prefix) {
 			continue
 		}
 
-		key, value := utils.SplitEnv(envStr)
-		key = utils.StripPrefix(key, prefix)
-		key = utils.NormalizeEnvKey(key)
+		name, raw := utils.SplitEnv(envStr)
+		envName := utils.StripPrefix(name, prefix)
+		origin := contract.Origin{Layer: contract.LayerEnv, File: "", Line: 0, EnvVar: name, Flag: "", Profile: ""}
+
+		key, ok := m.secretKey(envName)
+		if ok {
+			value, err := m.readSecret(name, strings.TrimSuffix(name, SecretFileSuffix), raw)
+			if err != nil {
+				errs = append(errs, err)
 
-		provider.Set(key, value)
+				continue
+			}
+
+			raw, origin.File = value, raw
+		} else if key, ok = m.mapKey(envName); !ok {
+			continue
+		}
+
+		m.set(provider, origins, key, raw, origin)
 	}
 
-	return nil
+	return errors2.Join(errs...)
+}
+
+// set stores raw at key, converted if m has a type for key, and records its origin.
+func (m mapping) set(provider contract.Provider, origins map[string]contract.Origin, key, raw string, origin contract.Origin) {
+	var value any = raw
+	if typ, ok := m.types[key]; ok {
+		value = coerce(raw, typ)
+	}
+
+	provider.Set(key, value)
+	origins[key] = origin
+}
+
+// Source returns where the loader reads variables from.
+//
+//nolint:ireturn // the source is only known by its interface
+func (l *Loader) Source() contract.EnvSource {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.source
 }
 
 // GetProvider returns the Provider associated with the Loader.
 //
 //nolint:ireturn // returning an interface is required by the contract API
 func (l *Loader) GetProvider() contract.Provider {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
 	return l.provider
 }
+
+// Compile time checks for interfaces.
+var (
+	_ contract.EnvLoader      = (*Loader)(nil)
+	_ contract.Reloader       = (*Loader)(nil)
+	_ contract.StagedReloader = (*Loader)(nil)
+	_ contract.OriginTracker  = (*Loader)(nil)
+	_ contract.EnvBinder      = (*Loader)(nil)
+)
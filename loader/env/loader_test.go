@@ -1,12 +1,19 @@
 package env_test
 
 import (
+	errors2 "errors"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/hbttundar/scg-config/config"
 	"github.com/hbttundar/scg-config/contract"
+	loaderErrors "github.com/hbttundar/scg-config/errors"
 	"github.com/hbttundar/scg-config/loader/env"
 	"github.com/hbttundar/scg-config/provider/viper"
+	"github.com/hbttundar/scg-config/schema"
 )
 
 func TestEnvLoader_LoadFromEnv(t *testing.T) {
@@ -95,3 +102,662 @@ func TestEnvLoader_LoadFromEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvLoader_ReloadAndOrigins(t *testing.T) {
+	t.Setenv("ORIGIN_SERVER_PORT", "8080")
+
+	provider := viper.NewConfigProvider()
+	loader := env.NewEnvLoader(provider)
+
+	if err := loader.LoadFromEnv("ORIGIN"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	want := contract.Origin{Layer: contract.LayerEnv, EnvVar: "ORIGIN_SERVER_PORT"}
+	if origin, ok := loader.Origin("server.port"); !ok || origin != want {
+		t.Errorf("Origin(server.port) = %+v, want %+v", origin, want)
+	}
+
+	t.Setenv("ORIGIN_SERVER_PORT", "9090")
+
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload error: %v", err)
+	}
+
+	if got := provider.GetKey("server.port"); got != "9090" {
+		t.Errorf("server.port = %v, want 9090 after reload", got)
+	}
+}
+
+func TestEnvLoader_ReloadIsAtomic(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(secret, []byte("s1"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	t.Setenv("ATOMIC_NAME", "v1")
+	t.Setenv("ATOMIC_TOKEN_FILE", secret)
+
+	provider := viper.NewConfigProvider()
+	loader := env.NewEnvLoader(provider, env.WithFileSecrets(9, "token"))
+
+	if err := loader.LoadFromEnv("ATOMIC"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	// The secret no longer reads, so the new name must not be applied either.
+	t.Setenv("ATOMIC_NAME", "v2")
+
+	if err := os.WriteFile(secret, []byte("0123456789"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	if err := loader.Reload(); !errors2.Is(err, loaderErrors.ErrSecretFileTooLarge) {
+		t.Fatalf("Reload error = %v, want ErrSecretFileTooLarge", err)
+	}
+
+	if got := provider.GetKey("name"); got != "v1" {
+		t.Errorf("name = %v, want v1 after a failed reload", got)
+	}
+
+	if err := os.WriteFile(secret, []byte("s2"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload error: %v", err)
+	}
+
+	if got := provider.GetKey("name"); got != "v2" {
+		t.Errorf("name = %v, want v2 after reload", got)
+	}
+
+	if got := provider.GetKey("token"); got != "s2" {
+		t.Errorf("token = %v, want s2 after reload", got)
+	}
+}
+
+func TestEnvLoader_StageReload(t *testing.T) {
+	t.Setenv("STAGE_SERVER_PORT", "8080")
+	t.Setenv("STAGE_SERVER_HOST", "localhost")
+
+	loader := env.NewEnvLoader(viper.NewConfigProvider())
+
+	if err := loader.LoadFromEnv("STAGE"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	t.Setenv("STAGE_SERVER_PORT", "9090")
+
+	if err := os.Unsetenv("STAGE_SERVER_HOST"); err != nil {
+		t.Fatalf("unsetenv: %v", err)
+	}
+
+	stage, err := loader.StageReload()
+	if err != nil {
+		t.Fatalf("StageReload error: %v", err)
+	}
+
+	if got := loader.GetProvider().GetKey("server.port"); got != "8080" {
+		t.Errorf("server.port = %v, want 8080 before commit", got)
+	}
+
+	stage.Commit()
+
+	if got := loader.GetProvider().GetKey("server.port"); got != "9090" {
+		t.Errorf("server.port = %v, want 9090 after commit", got)
+	}
+
+	if loader.GetProvider().IsSet("server.host") {
+		t.Error("server.host should be gone after an unset variable is staged")
+	}
+}
+
+func TestEnvLoader_NestedKeyMapper(t *testing.T) {
+	t.Setenv("NEST_DB__MAX_OPEN_CONNS", "25")
+	t.Setenv("NEST_LOG_LEVEL", "debug")
+	t.Setenv("NEST_HTTP__TLS__CERT_FILE", "/etc/cert.pem")
+
+	provider := viper.NewConfigProvider()
+	loader := env.NewEnvLoader(provider, env.WithKeyMapper(env.NestedKeyMapper("__")))
+
+	if err := loader.LoadFromEnv("NEST"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	want := map[string]string{
+		"db.max_open_conns":  "25",
+		"log_level":          "debug",
+		"http.tls.cert_file": "/etc/cert.pem",
+	}
+
+	for key, value := range want {
+		if got := provider.GetKey(key); got != value {
+			t.Errorf("%s = %#v, want %q", key, got, value)
+		}
+	}
+
+	if provider.IsSet("db.max.open.conns") || provider.IsSet("log.level") {
+		t.Error("single underscores must not nest")
+	}
+
+	origin, _ := loader.Origin("db.max_open_conns")
+	if origin.EnvVar != "NEST_DB__MAX_OPEN_CONNS" {
+		t.Errorf("origin env var = %q, want NEST_DB__MAX_OPEN_CONNS", origin.EnvVar)
+	}
+}
+
+func TestEnvLoader_CustomKeyMapper(t *testing.T) {
+	t.Setenv("HOOK_DATABASE_URL", "postgres://db")
+	t.Setenv("HOOK_INTERNAL_TOKEN", "secret")
+
+	mapper := func(envName string) (string, bool) {
+		switch envName {
+		case "DATABASE_URL":
+			return "db.dsn", true
+		case "INTERNAL_TOKEN":
+			return "", false
+		default:
+			return env.DefaultKeyMapper(envName)
+		}
+	}
+
+	provider := viper.NewConfigProvider()
+	if err := env.NewEnvLoader(provider, env.WithKeyMapper(mapper)).LoadFromEnv("HOOK"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	if got := provider.GetKey("db.dsn"); got != "postgres://db" {
+		t.Errorf("db.dsn = %#v, want postgres://db", got)
+	}
+
+	if provider.IsSet("internal.token") || provider.IsSet("database.url") {
+		t.Error("variables the mapper rejects or renames must not be set under the default key")
+	}
+}
+
+func TestEnvLoader_KeyMatching(t *testing.T) {
+	t.Setenv("MATCH_DB_MAX_OPEN_CONNS", "25")
+	t.Setenv("MATCH_SERVER__READ_TIMEOUT", "5s")
+	t.Setenv("MATCH_CACHE_TTL", "60")
+
+	provider := viper.NewConfigProvider()
+	loader := env.NewEnvLoader(provider, env.WithKeyMatching())
+	loader.SetKnownKeys(func() []string { return []string{"db.max_open_conns", "server.read_timeout"} })
+
+	if err := loader.LoadFromEnv("MATCH"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	want := map[string]string{
+		"db.max_open_conns":   "25",
+		"server.read_timeout": "5s",
+		"cache.ttl":           "60", // unknown: falls back to the key mapper
+	}
+
+	for key, value := range want {
+		if got := provider.GetKey(key); got != value {
+			t.Errorf("%s = %#v, want %q", key, got, value)
+		}
+	}
+}
+
+func TestEnvLoader_KeyMatchingWithConfig(t *testing.T) {
+	t.Setenv("KNOWN_DB_MAX_OPEN_CONNS", "25")
+	t.Setenv("KNOWN_SERVER_READ_TIMEOUT", "5s")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+
+	if err := os.WriteFile(path, []byte("db:\n  max_open_conns: 10\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	s := schema.New()
+	s.Field("server.read_timeout").Type(contract.Duration)
+
+	cfg := config.New(
+		config.WithEnvLoader(env.NewEnvLoader(viper.NewConfigProvider(), env.WithKeyMatching())),
+		config.WithSchema(s),
+	)
+
+	if err := cfg.FileLoader().LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile error: %v", err)
+	}
+
+	if err := cfg.EnvLoader().LoadFromEnv("KNOWN"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("Reload error: %v", err)
+	}
+
+	if got := config.MustGet[int](cfg, "db.max_open_conns"); got != 25 {
+		t.Errorf("db.max_open_conns = %d, want 25 from the environment", got)
+	}
+
+	if got := config.MustGet[time.Duration](cfg, "server.read_timeout"); got != 5*time.Second {
+		t.Errorf("server.read_timeout = %s, want 5s", got)
+	}
+}
+
+func TestEnvLoader_TypeCoercion(t *testing.T) {
+	envs := map[string]string{
+		"COERCE_PORT":     "8080",
+		"COERCE_DEBUG":    "true",
+		"COERCE_RATIO":    "0.5",
+		"COERCE_TIMEOUT":  "30s",
+		"COERCE_ROLES":    "admin, user",
+		"COERCE_IDS":      "[1, 2, 3]",
+		"COERCE_LABELS":   `{"team": "core", "tier": 1}`,
+		"COERCE_WORKERS":  "many",
+		"COERCE_NAME":     "scg",
+		"COERCE_UNTYPED":  "42",
+		"COERCE_EMPTY_LS": "",
+	}
+	for key, value := range envs {
+		t.Setenv(key, value)
+	}
+
+	provider := viper.NewConfigProvider()
+	loader := env.NewEnvLoader(provider, env.WithTypeCoercion())
+	loader.SetKeyTypes(func() map[string]contract.KeyType {
+		return map[string]contract.KeyType{
+			"port":     contract.Int,
+			"debug":    contract.Bool,
+			"ratio":    contract.Float64,
+			"timeout":  contract.Duration,
+			"roles":    contract.StringSlice,
+			"ids":      contract.StringSlice,
+			"labels":   contract.Map,
+			"workers":  contract.Int,
+			"name":     contract.String,
+			"empty.ls": contract.StringSlice,
+		}
+	})
+
+	if err := loader.LoadFromEnv("COERCE"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	want := map[string]any{
+		"port":     8080,
+		"debug":    true,
+		"ratio":    0.5,
+		"timeout":  30 * time.Second,
+		"roles":    []string{"admin", "user"},
+		"ids":      []any{1, 2, 3},
+		"labels":   map[string]any{"team": "core", "tier": 1},
+		"workers":  "many", // does not convert: kept as a string
+		"name":     "scg",
+		"untyped":  "42",
+		"empty.ls": []string{},
+	}
+
+	for key, value := range want {
+		if got := provider.GetKey(key); !reflect.DeepEqual(got, value) {
+			t.Errorf("%s = %#v, want %#v", key, got, value)
+		}
+	}
+}
+
+func TestEnvLoader_TypeCoercionWithConfig(t *testing.T) {
+	t.Setenv("TYPED_SERVER_PORT", "8080")
+	t.Setenv("TYPED_AUTH_ROLES", "admin,ops")
+	t.Setenv("TYPED_SERVER_TIMEOUT", "45s")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	content := "server:\n  port: 80\n  timeout: 30s\nauth:\n  roles: [user]\n"
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	s := schema.New()
+	s.Field("server.timeout").Type(contract.Duration)
+
+	cfg := config.New(
+		config.WithEnvLoader(env.NewEnvLoader(viper.NewConfigProvider(), env.WithTypeCoercion())),
+		config.WithSchema(s),
+	)
+
+	if err := cfg.FileLoader().LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile error: %v", err)
+	}
+
+	if err := cfg.EnvLoader().LoadFromEnv("TYPED"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("Reload error: %v", err)
+	}
+
+	if got, _ := cfg.Lookup("server.port"); got != 8080 {
+		t.Errorf("server.port = %#v, want the int 8080 like a YAML value", got)
+	}
+
+	if got, _ := cfg.Lookup("server.timeout"); got != 45*time.Second {
+		t.Errorf("server.timeout = %#v, want 45s as declared by the schema", got)
+	}
+
+	if got := config.MustGet[[]string](cfg, "auth.roles"); !reflect.DeepEqual(got, []string{"admin", "ops"}) {
+		t.Errorf("auth.roles = %#v, want [admin ops]", got)
+	}
+}
+
+func TestEnvLoader_Bind(t *testing.T) {
+	t.Setenv("PG_URL", "postgres://pg")
+	t.Setenv("PORT", "8080")
+	t.Setenv("BIND_SERVER_PORT", "9090")
+	t.Setenv("BIND_LOG_LEVEL", "info")
+
+	provider := viper.NewConfigProvider()
+	loader := env.NewEnvLoader(provider)
+
+	if err := loader.LoadFromEnv("BIND"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	if err := loader.Bind("database.url", "DATABASE_URL", "PG_URL"); err != nil {
+		t.Fatalf("Bind error: %v", err)
+	}
+
+	if err := loader.Bind("server.port", "PORT"); err != nil {
+		t.Fatalf("Bind error: %v", err)
+	}
+
+	want := map[string]string{
+		"database.url": "postgres://pg", // DATABASE_URL is unset: the alias is used
+		"server.port":  "8080",          // a binding wins over the prefix
+		"log.level":    "info",
+	}
+
+	for key, value := range want {
+		if got := provider.GetKey(key); got != value {
+			t.Errorf("%s = %#v, want %q", key, got, value)
+		}
+	}
+
+	if origin, _ := loader.Origin("database.url"); origin.EnvVar != "PG_URL" {
+		t.Errorf("database.url origin = %q, want PG_URL", origin.EnvVar)
+	}
+
+	// The first variable that is set wins, and bindings are read again on reload.
+	t.Setenv("DATABASE_URL", "postgres://primary")
+
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload error: %v", err)
+	}
+
+	if got := provider.GetKey("database.url"); got != "postgres://primary" {
+		t.Errorf("database.url = %#v after reload, want postgres://primary", got)
+	}
+
+	if got := provider.GetKey("server.port"); got != "8080" {
+		t.Errorf("server.port = %#v after reload, want the bound 8080", got)
+	}
+}
+
+func TestEnvLoader_BindDefaultName(t *testing.T) {
+	t.Setenv("CACHE_TTL", "60")
+
+	provider := viper.NewConfigProvider()
+	loader := env.NewEnvLoader(provider)
+
+	if err := loader.Bind("Cache.TTL"); err != nil {
+		t.Fatalf("Bind error: %v", err)
+	}
+
+	if got := provider.GetKey("cache.ttl"); got != "60" {
+		t.Errorf("cache.ttl = %#v, want \"60\" from CACHE_TTL", got)
+	}
+
+	if err := os.Unsetenv("CACHE_TTL"); err != nil {
+		t.Fatalf("unsetenv: %v", err)
+	}
+
+	stage, err := loader.StageReload()
+	if err != nil {
+		t.Fatalf("StageReload error: %v", err)
+	}
+
+	if stage.Provider().IsSet("cache.ttl") {
+		t.Error("an unset bound variable must be dropped by a staged reload")
+	}
+}
+
+func TestEnvLoader_BindWithoutProvider(t *testing.T) {
+	loader := env.NewEnvLoader(nil)
+
+	if err := loader.Bind("server.port", "PORT"); !errors2.Is(err, loaderErrors.ErrBackendProviderNotSet) {
+		t.Errorf("expected ErrBackendProviderNotSet, got %v", err)
+	}
+}
+
+func TestEnvLoader_Sources(t *testing.T) {
+	t.Parallel()
+
+	sources := map[string]func(vars map[string]string) env.Option{
+		"map": env.WithEnvMap,
+		"environ": func(vars map[string]string) env.Option {
+			return env.WithEnviron(func() []string { return env.MapSource(vars).Environ() })
+		},
+		"source": func(vars map[string]string) env.Option { return env.WithSource(env.MapSource(vars)) },
+	}
+
+	for name, option := range sources {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			vars := map[string]string{"SRC_SERVER_PORT": "8080", "DATABASE_URL": "postgres://db", "OTHER": "x"}
+
+			provider := viper.NewConfigProvider()
+			loader := env.NewEnvLoader(provider, option(vars))
+
+			if err := loader.LoadFromEnv("SRC"); err != nil {
+				t.Fatalf("LoadFromEnv error: %v", err)
+			}
+
+			if err := loader.Bind("database.url", "DATABASE_URL"); err != nil {
+				t.Fatalf("Bind error: %v", err)
+			}
+
+			if got := provider.GetKey("server.port"); got != "8080" {
+				t.Errorf("server.port = %#v, want \"8080\"", got)
+			}
+
+			if got := provider.GetKey("database.url"); got != "postgres://db" {
+				t.Errorf("database.url = %#v, want postgres://db", got)
+			}
+
+			// The source is read again on reload.
+			vars["SRC_SERVER_PORT"] = "9090"
+
+			if err := loader.Reload(); err != nil {
+				t.Fatalf("Reload error: %v", err)
+			}
+
+			if got := provider.GetKey("server.port"); got != "9090" {
+				t.Errorf("server.port = %#v after reload, want \"9090\"", got)
+			}
+		})
+	}
+}
+
+func TestEnvironFunc_LookupEnv(t *testing.T) {
+	t.Parallel()
+
+	source := env.EnvironFunc(func() []string { return []string{"A=1", "B", "A=2", "C="} })
+
+	for name, want := range map[string]struct {
+		value string
+		found bool
+	}{
+		"A": {value: "2", found: true}, // the last definition wins
+		"B": {value: "", found: false}, // no "=": not a variable
+		"C": {value: "", found: true},
+		"D": {value: "", found: false},
+	} {
+		value, found := source.LookupEnv(name)
+		if value != want.value || found != want.found {
+			t.Errorf("LookupEnv(%q) = %q, %v, want %q, %v", name, value, found, want.value, want.found)
+		}
+	}
+}
+
+func TestEnvLoader_FileSecrets(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "db_password")
+
+	if err := os.WriteFile(secret, []byte("s3cret\r\n"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	provider := viper.NewConfigProvider()
+	loader := env.NewEnvLoader(provider, env.WithFileSecrets(0, "db.password"), env.WithEnvMap(map[string]string{
+		"APP_DB_PASSWORD_FILE": secret,
+		"APP_DB_USER":          "admin",
+		"APP_LOG_FILE":         "/nonexistent/app.log",
+	}))
+
+	if err := loader.LoadFromEnv("APP"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	if got := provider.GetKey("db.password"); got != "s3cret" {
+		t.Errorf("db.password = %#v, want \"s3cret\"", got)
+	}
+
+	if got := provider.GetKey("db.user"); got != "admin" {
+		t.Errorf("db.user = %#v, want \"admin\"", got)
+	}
+
+	if provider.IsSet("db.password.file") {
+		t.Error("db.password.file should not be set")
+	}
+
+	// Keys that are not secrets keep their _FILE variables as plain values.
+	if got := provider.GetKey("log.file"); got != "/nonexistent/app.log" {
+		t.Errorf("log.file = %#v, want \"/nonexistent/app.log\"", got)
+	}
+
+	origin, ok := loader.Origin("db.password")
+	if !ok || origin.EnvVar != "APP_DB_PASSWORD_FILE" || origin.File != secret {
+		t.Errorf("Origin(db.password) = %+v, %v, want APP_DB_PASSWORD_FILE from %s", origin, ok, secret)
+	}
+
+	// Without WithFileSecrets the variable is an ordinary one.
+	plain := viper.NewConfigProvider()
+	if err := env.NewEnvLoader(plain, env.WithEnvMap(map[string]string{"APP_DB_PASSWORD_FILE": secret})).
+		LoadFromEnv("APP"); err != nil {
+		t.Fatalf("LoadFromEnv error: %v", err)
+	}
+
+	if got := plain.GetKey("db.password.file"); got != secret {
+		t.Errorf("db.password.file = %#v, want %q", got, secret)
+	}
+}
+
+func TestEnvLoader_FileSecretsErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	large := filepath.Join(dir, "large")
+	exact := filepath.Join(dir, "exact")
+
+	if err := os.WriteFile(large, []byte("0123456789"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	if err := os.WriteFile(exact, []byte("01234567\n"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		vars map[string]string
+		want error
+	}{
+		{
+			name: "missing file",
+			vars: map[string]string{"APP_TOKEN_FILE": filepath.Join(dir, "missing")},
+			want: loaderErrors.ErrSecretFileFailed,
+		},
+		{
+			name: "too large",
+			vars: map[string]string{"APP_TOKEN_FILE": large},
+			want: loaderErrors.ErrSecretFileTooLarge,
+		},
+		{
+			name: "conflict",
+			vars: map[string]string{"APP_TOKEN_FILE": exact, "APP_TOKEN": "plain"},
+			want: loaderErrors.ErrSecretConflict,
+		},
+		{
+			name: "at the limit",
+			vars: map[string]string{"APP_TOKEN_FILE": exact},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			vars := map[string]string{"APP_NAME": "demo"}
+			for name, value := range tt.vars {
+				vars[name] = value
+			}
+
+			provider := viper.NewConfigProvider()
+			loader := env.NewEnvLoader(provider, env.WithFileSecrets(9, "token"), env.WithEnvMap(vars))
+
+			err := loader.LoadFromEnv("APP")
+			if !errors2.Is(err, tt.want) || (tt.want == nil) != (err == nil) {
+				t.Fatalf("LoadFromEnv error = %v, want %v", err, tt.want)
+			}
+
+			// Other variables are loaded regardless.
+			if got := provider.GetKey("name"); got != "demo" {
+				t.Errorf("name = %#v, want \"demo\"", got)
+			}
+
+			if _, err := loader.StageReload(); !errors2.Is(err, tt.want) || (tt.want == nil) != (err == nil) {
+				t.Errorf("StageReload error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvLoader_BindFileSecret(t *testing.T) {
+	t.Parallel()
+
+	secret := filepath.Join(t.TempDir(), "database_url")
+	if err := os.WriteFile(secret, []byte("postgres://secret\n"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	provider := viper.NewConfigProvider()
+	loader := env.NewEnvLoader(provider, env.WithFileSecrets(0), env.WithEnvMap(map[string]string{
+		"DATABASE_URL_FILE": secret,
+		"PG_URL":            "postgres://fallback",
+	}))
+
+	if err := loader.Bind("database.url", "DATABASE_URL", "PG_URL"); err != nil {
+		t.Fatalf("Bind error: %v", err)
+	}
+
+	if got := provider.GetKey("database.url"); got != "postgres://secret" {
+		t.Errorf("database.url = %#v, want \"postgres://secret\"", got)
+	}
+
+	origin, _ := loader.Origin("database.url")
+	if origin.EnvVar != "DATABASE_URL_FILE" || origin.File != secret {
+		t.Errorf("Origin(database.url) = %+v, want DATABASE_URL_FILE from %s", origin, secret)
+	}
+}
@@ -0,0 +1,111 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hbttundar/scg-config/contract"
+	loaderErrors "github.com/hbttundar/scg-config/errors"
+)
+
+const (
+	// SecretFileSuffix marks a variable that holds the path of a file with the value,
+	// such as APP_DB_PASSWORD_FILE=/run/secrets/db_password.
+	SecretFileSuffix = "_FILE"
+	// DefaultMaxSecretSize is the largest secret file WithFileSecrets reads by default.
+	DefaultMaxSecretSize = 64 << 10
+)
+
+// WithFileSecrets makes the loader read the given keys from files, the convention
+// Docker and Kubernetes use for secrets: with key "db.password",
+// APP_DB_PASSWORD_FILE=/run/secrets/db_password sets db.password to the file's
+// contents, without its trailing newline. Other variables ending in _FILE, such as
+// APP_LOG_FILE, are loaded as usual. Bound variables may always be given as files, so
+// Bind("database.url", "DATABASE_URL") reads DATABASE_URL_FILE when it is set instead.
+// Files larger than maxSize bytes (DefaultMaxSecretSize when maxSize is not positive)
+// are rejected, and so is setting both a variable and its _FILE variant. Secret files
+// are read again on every reload.
+func WithFileSecrets(maxSize int64, keys ...string) Option {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSecretSize
+	}
+
+	secretKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		secretKeys = append(secretKeys, strings.ToLower(key))
+	}
+
+	return func(l *Loader) {
+		l.maxSecretSize = maxSize
+		l.secretKeys = secretKeys
+	}
+}
+
+// secretKey returns the key the _FILE variable name, without prefix, stands for, if m
+// reads that key from files.
+func (m mapping) secretKey(name string) (string, bool) {
+	if m.maxSecretSize <= 0 {
+		return "", false
+	}
+
+	target, ok := strings.CutSuffix(name, SecretFileSuffix)
+	if !ok || target == "" {
+		return "", false
+	}
+
+	key, ok := m.mapKey(target)
+
+	return key, ok && slices.Contains(m.secretKeys, key)
+}
+
+// readSecret reads the value of the _FILE variable name, whose value is path, making
+// sure the variable it stands for is not set as well.
+func (m mapping) readSecret(name, target, path string) (string, error) {
+	if _, set := m.source.LookupEnv(target); set {
+		return "", fmt.Errorf("%w: %s and %s", loaderErrors.ErrSecretConflict, target, name)
+	}
+
+	file, err := os.Open(path) // #nosec G304 -- the path is the point of the _FILE convention
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", loaderErrors.ErrSecretFileFailed, name, err)
+	}
+	defer file.Close()
+
+	// Read one byte more than allowed to tell a file of exactly maxSize from a larger one
+	data, err := io.ReadAll(io.LimitReader(file, m.maxSecretSize+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", loaderErrors.ErrSecretFileFailed, name, err)
+	}
+
+	if int64(len(data)) > m.maxSecretSize {
+		return "", fmt.Errorf("%w: %s: %s is larger than %d bytes",
+			loaderErrors.ErrSecretFileTooLarge, name, path, m.maxSecretSize)
+	}
+
+	value := strings.TrimSuffix(string(data), "\n")
+
+	return strings.TrimSuffix(value, "\r"), nil
+}
+
+// lookup returns the value of the variable name, or of name_FILE when m reads secret
+// files, and where it came from.
+func (m mapping) lookup(name string) (string, contract.Origin, bool, error) {
+	origin := contract.Origin{Layer: contract.LayerEnv, File: "", Line: 0, EnvVar: name, Flag: "", Profile: ""}
+
+	if m.maxSecretSize > 0 {
+		fileVar := name + SecretFileSuffix
+		if path, ok := m.source.LookupEnv(fileVar); ok {
+			value, err := m.readSecret(fileVar, name, path)
+			origin.EnvVar, origin.File = fileVar, path
+
+			return value, origin, err == nil, err
+		}
+	}
+
+	value, ok := m.source.LookupEnv(name)
+
+	return value, origin, ok, nil
+}
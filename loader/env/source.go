@@ -0,0 +1,83 @@
+package env
+
+import (
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hbttundar/scg-config/contract"
+)
+
+// ProcessSource reads the environment of the current process. It is the default.
+type ProcessSource struct{}
+
+func (ProcessSource) Environ() []string { return os.Environ() }
+
+func (ProcessSource) LookupEnv(name string) (string, bool) { return os.LookupEnv(name) }
+
+// MapSource serves the variables of a map. It is read each time the loader reads the
+// environment, so changing the map and reloading picks the change up. Variables are
+// listed in sorted order, so the result does not depend on map iteration.
+type MapSource map[string]string
+
+func (m MapSource) Environ() []string {
+	environ := make([]string, 0, len(m))
+	for _, name := range slices.Sorted(maps.Keys(m)) {
+		environ = append(environ, name+"="+m[name])
+	}
+
+	return environ
+}
+
+func (m MapSource) LookupEnv(name string) (string, bool) {
+	value, ok := m[name]
+
+	return value, ok
+}
+
+// EnvironFunc serves the "NAME=value" pairs returned by a function, such as a
+// captured copy of os.Environ.
+type EnvironFunc func() []string
+
+func (f EnvironFunc) Environ() []string { return f() }
+
+func (f EnvironFunc) LookupEnv(name string) (string, bool) {
+	// Like os.LookupEnv, the last definition of a name wins
+	var (
+		value string
+		found bool
+	)
+
+	for _, envStr := range f() {
+		if key, val, ok := strings.Cut(envStr, "="); ok && key == name {
+			value, found = val, true
+		}
+	}
+
+	return value, found
+}
+
+// WithSource makes the loader read variables from source instead of the process
+// environment, so tests can run in parallel without os.Setenv.
+func WithSource(source contract.EnvSource) Option {
+	return func(l *Loader) { l.source = source }
+}
+
+// WithEnvMap makes the loader read variables from vars (see MapSource).
+func WithEnvMap(vars map[string]string) Option {
+	return WithSource(MapSource(vars))
+}
+
+// WithEnviron makes the loader read the "NAME=value" pairs environ returns (see
+// EnvironFunc).
+func WithEnviron(environ func() []string) Option {
+	return WithSource(EnvironFunc(environ))
+}
+
+// Compile time checks for interface.
+var (
+	_ contract.EnvSource = ProcessSource{}
+	_ contract.EnvSource = MapSource(nil)
+	_ contract.EnvSource = EnvironFunc(nil)
+)
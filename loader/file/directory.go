@@ -0,0 +1,246 @@
+package file
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hbttundar/scg-config/decoder"
+	"github.com/hbttundar/scg-config/errors"
+)
+
+// SymlinkPolicy says what LoadFromDirectory does with symbolic links.
+type SymlinkPolicy int
+
+const (
+	// SymlinkFiles loads symlinked files but does not descend into symlinked
+	// directories. This is the default, and works with Kubernetes ConfigMap volumes.
+	SymlinkFiles SymlinkPolicy = iota
+	// SymlinkFollow also descends into symlinked directories when loading recursively.
+	// A directory that links back to one already visited is skipped.
+	SymlinkFollow
+	// SymlinkIgnore skips every symlink.
+	SymlinkIgnore
+)
+
+// Option is a functional option for configuring the Loader.
+type Option func(*Loader)
+
+// WithNamespaceByFilename makes LoadFromDirectory nest every file under its base name,
+// so that database.json sets database.* and two files with a timeout key no longer
+// clobber each other. Subdirectories are loaded too and add one level each:
+// services/billing.yaml sets services.billing.*. A file without a base name, such as
+// .env, stays at the namespace of its directory.
+func WithNamespaceByFilename() Option {
+	return func(l *Loader) {
+		l.walk.namespaced = true
+		l.walk.recursive = true
+	}
+}
+
+// WithRecursive makes LoadFromDirectory descend into subdirectories. A directory's own
+// files are loaded first, then each subdirectory in alphabetical order, so files deeper
+// in the tree win. Hidden directories, such as .git or the ..data directories of a
+// ConfigMap volume, are skipped.
+func WithRecursive() Option {
+	return func(l *Loader) { l.walk.recursive = true }
+}
+
+// WithInclude makes LoadFromDirectory load only the files that match at least one of
+// patterns. See WithExclude for how patterns are matched.
+func WithInclude(patterns ...string) Option {
+	return func(l *Loader) { l.walk.include = append(l.walk.include, patterns...) }
+}
+
+// WithExclude makes LoadFromDirectory skip the files and directories that match any of
+// patterns, such as "*.example.yaml" or "_*". Patterns use path.Match syntax. A pattern
+// without a slash is matched against the name alone, at any depth; one with a slash is
+// matched against the path relative to the loaded directory, e.g. "services/legacy".
+func WithExclude(patterns ...string) Option {
+	return func(l *Loader) { l.walk.exclude = append(l.walk.exclude, patterns...) }
+}
+
+// WithSymlinks sets what LoadFromDirectory does with symbolic links. The default is
+// SymlinkFiles.
+func WithSymlinks(policy SymlinkPolicy) Option {
+	return func(l *Loader) { l.walk.symlinks = policy }
+}
+
+// walkOptions controls which files a directory expands to.
+type walkOptions struct {
+	recursive  bool
+	namespaced bool
+	include    []string
+	exclude    []string
+	symlinks   SymlinkPolicy
+}
+
+// walker collects the config files of one directory tree.
+type walker struct {
+	opts    walkOptions
+	visited map[string]bool
+	files   []configFile
+	dirs    []string
+}
+
+// list returns the config files under root, in merge order.
+func (o walkOptions) list(root string) ([]configFile, error) {
+	w, err := o.walk(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.files, nil
+}
+
+// walk walks the tree under root and returns the walker with the files and directories
+// it found.
+func (o walkOptions) walk(root string) (*walker, error) {
+	for _, pattern := range slices.Concat(o.include, o.exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: %q", errors.ErrInvalidGlobPattern, pattern)
+		}
+	}
+
+	w := &walker{opts: o, visited: make(map[string]bool), files: nil, dirs: nil}
+	if err := w.walkDir(root, ""); err != nil {
+		return nil, fmt.Errorf("%w: %w", errors.ErrFailedReadDirectory, err)
+	}
+
+	return w, nil
+}
+
+// walkDir adds the config files in dir, whose path relative to the root is rel, and
+// then those of its subdirectories.
+func (w *walker) walkDir(dir, rel string) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+
+	if w.visited[realDir] {
+		return nil
+	}
+
+	w.visited[realDir] = true
+	w.dirs = append(w.dirs, dir)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var subdirs []fs.DirEntry
+
+	for _, entry := range entries {
+		name := entry.Name()
+		entryRel := path.Join(rel, name)
+
+		isDir, ok := w.kind(filepath.Join(dir, name), entry)
+		if !ok || matchAny(w.opts.exclude, entryRel) {
+			continue
+		}
+
+		if isDir {
+			if w.opts.recursive && !strings.HasPrefix(name, ".") {
+				subdirs = append(subdirs, entry)
+			}
+
+			continue
+		}
+
+		if !decoder.Supports(name) || (len(w.opts.include) > 0 && !matchAny(w.opts.include, entryRel)) {
+			continue
+		}
+
+		namespace := ""
+		if w.opts.namespaced {
+			namespace = namespaceFor(entryRel)
+		}
+
+		w.files = append(w.files, configFile{path: filepath.Join(dir, name), namespace: namespace, profile: ""})
+	}
+
+	for _, entry := range subdirs {
+		if err := w.walkDir(filepath.Join(dir, entry.Name()), path.Join(rel, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// kind reports whether entry is a directory, following symlinks as the policy allows.
+// ok is false for entries to skip, such as dangling or ignored symlinks.
+func (w *walker) kind(entryPath string, entry fs.DirEntry) (isDir, ok bool) {
+	if entry.Type()&fs.ModeSymlink == 0 {
+		return entry.IsDir(), true
+	}
+
+	if w.opts.symlinks == SymlinkIgnore {
+		return false, false
+	}
+
+	info, err := os.Stat(entryPath)
+	if err != nil {
+		return false, false
+	}
+
+	if info.IsDir() {
+		return true, w.opts.symlinks == SymlinkFollow
+	}
+
+	return false, true
+}
+
+// matchAny reports whether rel, a slash-separated path relative to the loaded
+// directory, matches one of patterns.
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		target := rel
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(rel)
+		}
+
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// namespaceFor turns a path relative to the loaded directory into a dotted namespace:
+// services/billing.yaml becomes services.billing.
+func namespaceFor(rel string) string {
+	parts := strings.Split(path.Dir(rel), "/")
+	if parts[0] == "." {
+		parts = parts[:0]
+	}
+
+	base := path.Base(rel)
+	if name := strings.TrimSuffix(base, path.Ext(base)); name != "" {
+		parts = append(parts, name)
+	}
+
+	return strings.ToLower(strings.Join(parts, "."))
+}
+
+// nest places settings under the dotted namespace. An empty namespace leaves them at
+// the root.
+func nest(namespace string, settings map[string]any) map[string]any {
+	if namespace == "" {
+		return settings
+	}
+
+	parts := strings.Split(namespace, ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		settings = map[string]any{parts[i]: settings}
+	}
+
+	return settings
+}
@@ -2,120 +2,402 @@
 package file
 
 import (
+	errors2 "errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 
 	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/decoder"
 	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/internal/stage"
 	"github.com/hbttundar/scg-config/provider/viper"
-	"github.com/hbttundar/scg-config/utils"
 )
 
+// source is a file or directory passed to the loader, remembered so it can be re-read.
+type source struct {
+	path     string
+	dir      bool
+	profiles []string
+}
+
+// equal reports whether s and other are the same source.
+func (s source) equal(other source) bool {
+	return s.path == other.path && s.dir == other.dir && slices.Equal(s.profiles, other.profiles)
+}
+
+// configFile is a file to load, the dotted namespace its keys are nested under and the
+// profile it is an overlay for, if any.
+type configFile struct {
+	path      string
+	namespace string
+	profile   string
+}
+
 // Loader loads configuration files into the provider provider.
 type Loader struct {
 	provider contract.Provider
+	sources  []source
+	origins  map[string]contract.Origin
+	walk     walkOptions
+	mu       sync.RWMutex
 }
 
 // NewFileLoader creates a new Loader for the given provider provider.
-func NewFileLoader(p contract.Provider) *Loader {
-	return &Loader{provider: p}
+func NewFileLoader(p contract.Provider, opts ...Option) *Loader {
+	loader := &Loader{
+		provider: p,
+		sources:  nil,
+		origins:  make(map[string]contract.Origin),
+		walk: walkOptions{
+			recursive:  false,
+			namespaced: false,
+			include:    nil,
+			exclude:    nil,
+			symlinks:   SymlinkFiles,
+		},
+		mu: sync.RWMutex{},
+	}
+	for _, opt := range opts {
+		opt(loader)
+	}
+
+	return loader
 }
 
 // LoadFromFile loads a single configuration file into the provider.
+// Files loaded after the first are merged on top of it, so later files win.
 func (l *Loader) LoadFromFile(configFile string) error {
-	provider := l.provider
-	if provider == nil {
+	return l.addSource(source{path: configFile, dir: false, profiles: nil})
+}
+
+// LoadFromDirectory loads all supported config files from a directory.
+// Files are processed in alphabetical order, with the first file loaded normally
+// and subsequent files merged to preserve nested block structures. Every file is
+// merged at the root unless the loader was created WithNamespaceByFilename.
+// Subdirectories are only loaded WithRecursive, after the files next to them, and
+// WithInclude, WithExclude and WithSymlinks narrow down which files are loaded.
+func (l *Loader) LoadFromDirectory(dir string) error {
+	return l.addSource(source{path: dir, dir: true, profiles: nil})
+}
+
+// LoadProfile loads the base files of dir, such as app.yaml, and then the overlays of
+// each profile in order, such as app.staging.yaml and app.local.yaml for profiles
+// "staging" and "local", so later profiles win. Base files are the supported files
+// whose name has no dot before the extension; overlays of other profiles are skipped.
+// The directory is listed like LoadFromDirectory does, so the loader's options apply.
+func (l *Loader) LoadProfile(dir string, profiles ...string) error {
+	return l.addSource(source{path: dir, dir: true, profiles: normalizeProfiles(profiles)})
+}
+
+// ListDirectory returns the config files LoadFromDirectory loads from dir, in merge
+// order, and the directories it looks in, following the loader's options.
+func (l *Loader) ListDirectory(dir string) ([]string, []string, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	w, err := l.walk.walk(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := make([]string, 0, len(w.files))
+	for _, file := range w.files {
+		files = append(files, file.path)
+	}
+
+	return files, w.dirs, nil
+}
+
+// StageProfile reads every source loaded so far and then dir with the given profiles,
+// as LoadProfile would, into a fresh provider. The loader is left as it is until the
+// returned stage is committed.
+//
+//nolint:ireturn // returning an interface is required by the contract API
+func (l *Loader) StageProfile(dir string, profiles ...string) (contract.Stage, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.provider == nil {
+		return nil, errors.ErrBackendProviderHasNoConfig
+	}
+
+	src := source{path: dir, dir: true, profiles: normalizeProfiles(profiles)}
+
+	sources := l.sources
+	if !slices.ContainsFunc(sources, src.equal) {
+		sources = append(slices.Clone(sources), src)
+	}
+
+	provider, origins, err := l.readSources(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	return stage.New(provider, origins, func(provider contract.Provider, origins map[string]contract.Origin) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.provider, l.origins, l.sources = provider, origins, sources
+	}, applyConfig), nil
+}
+
+// Reload re-reads every file and directory loaded so far, in the original order.
+// Directories are listed again, so files added to them since are picked up.
+func (l *Loader) Reload() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.provider == nil {
 		return errors.ErrBackendProviderHasNoConfig
 	}
 
-	provider.SetConfigFile(configFile)
+	return l.replayLocked()
+}
 
-	if err := provider.ReadInConfig(); err != nil {
-		return fmt.Errorf("%w: %w", errors.ErrReadConfigFileFailed, err)
+// Origin returns the file and line that set key, if it was loaded by this loader.
+func (l *Loader) Origin(key string) (contract.Origin, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	origin, ok := l.origins[key]
+
+	return origin, ok
+}
+
+// addSource records src and loads it. Loading a source twice re-reads everything so
+// that the merge order stays the same.
+func (l *Loader) addSource(src source) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.provider == nil {
+		return errors.ErrBackendProviderHasNoConfig
+	}
+
+	if slices.ContainsFunc(l.sources, src.equal) {
+		return l.replayLocked()
+	}
+
+	files, err := l.sourceFiles(src)
+	if err != nil {
+		return err
+	}
+
+	if err := l.loadFilesLocked(files, len(l.sources) == 0); err != nil {
+		return err
 	}
 
+	l.sources = append(l.sources, src)
+
 	return nil
 }
 
-// LoadFromDirectory loads all supported config files from a directory.
-// Files are processed in alphabetical order, with the first file loaded normally
-// and subsequent files merged to preserve nested block structures.
-func (l *Loader) LoadFromDirectory(dir string) error {
-	provider := l.provider
-	if provider == nil {
-		return errors.ErrBackendProviderHasNoConfig
+// replayLocked re-reads all recorded sources into a fresh provider and, once every file
+// has been read, replaces the provider's file config with them in a single step, so a
+// file with a syntax error leaves the provider as it was. Assumes l.mu is held.
+func (l *Loader) replayLocked() error {
+	provider, origins, err := l.readSources(l.sources)
+	if err != nil {
+		return err
+	}
+
+	if err := applyConfig(provider, l.provider); err != nil {
+		return err
 	}
 
-	files, err := os.ReadDir(dir)
+	l.origins = origins
+
+	return nil
+}
+
+// StageReload re-reads every source into a fresh provider and leaves the loader as it
+// is until the returned stage is committed, so a file with a syntax error never leaves
+// the loader half-updated.
+//
+//nolint:ireturn // returning an interface is required by the contract API
+func (l *Loader) StageReload() (contract.Stage, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	provider, origins, err := l.readSources(l.sources)
 	if err != nil {
-		return fmt.Errorf("%w: %w", errors.ErrFailedReadDirectory, err)
+		return nil, err
 	}
 
-	// Filter and collect supported config files
-	var configFiles []string
+	return stage.New(provider, origins, l.commit, applyConfig), nil
+}
 
-	for _, file := range files {
-		if file.IsDir() || !utils.IsSupportedConfigFile(file.Name()) {
-			continue
-		}
+// readSources reads sources into a fresh provider. Assumes l.mu is held.
+func (l *Loader) readSources(sources []source) (contract.Provider, map[string]contract.Origin, error) {
+	files, err := l.sourceFilesOf(sources)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		configFiles = append(configFiles, file.Name())
+	provider, origins := viper.NewConfigProvider(), make(map[string]contract.Origin)
+	if err := loadFiles(provider, origins, files, true); err != nil {
+		return nil, nil, err
 	}
 
-	if len(configFiles) == 0 {
-		return nil // No config files found, not an error
+	return provider, origins, nil
+}
+
+// commit makes the loader serve a staged provider and origins.
+func (l *Loader) commit(provider contract.Provider, origins map[string]contract.Origin) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.provider = provider
+	l.origins = origins
+}
+
+// sourceFilesOf expands sources to their files, in merge order.
+func (l *Loader) sourceFilesOf(sources []source) ([]configFile, error) {
+	var files []configFile
+
+	for _, src := range sources {
+		srcFiles, err := l.sourceFiles(src)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, srcFiles...)
 	}
 
-	isFirst := true
+	return files, nil
+}
 
-	for _, fileName := range configFiles {
-		path := filepath.Join(dir, fileName)
+// loadFilesLocked loads files into the loader's provider. Assumes l.mu is held.
+func (l *Loader) loadFilesLocked(files []configFile, reset bool) error {
+	return loadFiles(l.provider, l.origins, files, reset)
+}
 
-		if isFirst {
-			// Load the first file normally to establish the base configuration
-			provider.SetConfigFile(path)
+// loadFiles loads files into provider in order, recording where each key came from in
+// origins. When reset is true the first file replaces the provider's file config; every
+// other file is merged on top.
+func loadFiles(provider contract.Provider, origins map[string]contract.Origin, files []configFile, reset bool) error {
+	for idx, file := range files {
+		path := file.path
 
-			if err := provider.ReadInConfig(); err != nil {
-				return fmt.Errorf("failed to load initial config file %s: %w", path, err)
-			}
+		decoded, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", errors.ErrReadConfigFileFailed, path, err)
+		}
+
+		configMap := nest(file.namespace, decoded)
 
-			isFirst = false
-		} else {
-			// For subsequent files, use a more robust merging approach
-			if err := l.mergeConfigFile(path); err != nil {
-				return fmt.Errorf("failed to merge config file %s: %w", path, err)
+		if idx == 0 && reset {
+			// The first file replaces whatever the provider read before
+			if err := replaceConfig(provider, path, configMap); err != nil {
+				return fmt.Errorf("%w: %s: %w", errors.ErrReadConfigFileFailed, path, err)
 			}
+		} else if err := provider.MergeConfigMap(configMap); err != nil {
+			// Subsequent files are merged to preserve nested block structures
+			return fmt.Errorf("failed to merge config file %s: %w", path, err)
 		}
+
+		recordOrigins(origins, file, decoded)
 	}
 
 	return nil
 }
 
-// mergeConfigFile merges a configuration file into the existing provider configuration.
-// This method uses the contract's MergeConfigMap method for better abstraction and
-// handles complex nested block structures more reliably.
-func (l *Loader) mergeConfigFile(configFile string) error {
-	// Create a temporary provider to load the file we want to merge
-	tempProvider := viper.NewConfigProvider()
-	tempProvider.SetConfigFile(configFile)
+// sourceFiles returns the config files src currently expands to, in merge order.
+func (l *Loader) sourceFiles(src source) ([]configFile, error) {
+	if !src.dir {
+		return []configFile{{path: src.path, namespace: "", profile: ""}}, nil
+	}
 
-	if err := tempProvider.ReadInConfig(); err != nil {
-		return fmt.Errorf("failed to read config file for merging: %w", err)
+	files, err := l.walk.list(src.path)
+	if err != nil || src.profiles == nil {
+		return files, err
 	}
 
-	// Get the configuration as a map and merge it using the contract method
-	configMap := tempProvider.AllSettings()
-	if err := l.provider.MergeConfigMap(configMap); err != nil {
-		return fmt.Errorf("failed to merge configuration map: %w", err)
+	return profileFiles(files, src.profiles), nil
+}
+
+// configReplacer is implemented by providers that can swap their file config for an
+// already decoded map, such as the Viper provider.
+type configReplacer interface {
+	ReplaceConfig(settings map[string]any) error
+}
+
+// replaceConfig makes configMap, decoded from path, the provider's file config.
+// Providers that cannot take a map read the file themselves.
+func replaceConfig(provider contract.Provider, path string, configMap map[string]any) error {
+	provider.SetConfigFile(path)
+
+	if replacer, ok := provider.(configReplacer); ok {
+		return replacer.ReplaceConfig(configMap)
+	}
+
+	return provider.ReadInConfig()
+}
+
+// applyConfig makes the files read into staged the file config of target, keeping the
+// values set on target with Set. Providers that cannot replace their file config have
+// the files merged on top of it instead.
+func applyConfig(staged, target contract.Provider) error {
+	replacer, ok := target.(configReplacer)
+	if !ok {
+		if err := target.MergeConfigMap(staged.AllSettings()); err != nil {
+			return fmt.Errorf("failed to merge config files: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := replacer.ReplaceConfig(staged.AllSettings()); err != nil {
+		return fmt.Errorf("failed to replace config files: %w", err)
 	}
 
 	return nil
 }
 
+// readConfigFile parses a single file with the decoder registered for its extension.
+// Data the decoder rejects is reported as errors.ErrParseConfigFileFailed.
+func readConfigFile(configFile string) (map[string]any, error) {
+	dec, ok := decoder.For(configFile)
+	if !ok {
+		return nil, fmt.Errorf("unsupported config file extension %q", filepath.Ext(configFile))
+	}
+
+	data, err := os.ReadFile(configFile) // #nosec G304 -- path comes from the caller's own config sources
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	settings, err := dec.Decode(data)
+	if err != nil {
+		if errors2.Is(err, errors.ErrParseConfigFileFailed) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w: %w", errors.ErrParseConfigFileFailed, err)
+	}
+
+	return settings, nil
+}
+
 // GetProvider returns the Provider associated with the Loader.
 //
 //nolint:ireturn // returning an interface is required by the contract API
 func (l *Loader) GetProvider() contract.Provider {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
 	return l.provider
 }
+
+// Compile time checks for interfaces.
+var (
+	_ contract.FileLoader      = (*Loader)(nil)
+	_ contract.Reloader        = (*Loader)(nil)
+	_ contract.StagedReloader  = (*Loader)(nil)
+	_ contract.OriginTracker   = (*Loader)(nil)
+	_ contract.ProfileLoader   = (*Loader)(nil)
+	_ contract.DirectoryLister = (*Loader)(nil)
+)
@@ -1,12 +1,15 @@
 package file_test
 
 import (
+	errors2 "errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/hbttundar/scg-config/config"
 	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
 	"github.com/hbttundar/scg-config/loader/file"
 	"github.com/hbttundar/scg-config/provider/viper"
 )
@@ -44,6 +47,41 @@ func TestFileLoader_LoadFromFile_AllSupportedExtensions(t *testing.T) {
 			key:     "app.name",
 			want:    "scg",
 		},
+		{
+			name:    "toml",
+			ext:     ".toml",
+			content: "[app]\nname = \"scg\"\n",
+			key:     "app.name",
+			want:    "scg",
+		},
+		{
+			name:    "hcl",
+			ext:     ".hcl",
+			content: "app {\n  name = \"scg\"\n}\n",
+			key:     "app.name",
+			want:    "scg",
+		},
+		{
+			name:    "ini",
+			ext:     ".ini",
+			content: "[app]\nname = scg\n",
+			key:     "app.name",
+			want:    "scg",
+		},
+		{
+			name:    "properties",
+			ext:     ".properties",
+			content: "app.name = scg\n",
+			key:     "app.name",
+			want:    "scg",
+		},
+		{
+			name:    "env",
+			ext:     ".env",
+			content: "APP_NAME=scg\n",
+			key:     "app.name",
+			want:    "scg",
+		},
 	}
 
 	for _, testCase := range cases {
@@ -78,3 +116,593 @@ func TestFileLoader_LoadFromFile_AllSupportedExtensions(t *testing.T) {
 		})
 	}
 }
+
+func TestFileLoader_LoadFromDirectory_ReloadAndOrigins(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	appFile := filepath.Join(dir, "app.yaml")
+	dbFile := filepath.Join(dir, "database.json")
+
+	if err := os.WriteFile(appFile, []byte("app:\n  name: scg\n  port: 8080\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := os.WriteFile(dbFile, []byte("{\n  \"app\": {\n    \"port\": 9090\n  }\n}\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	provider := viper.NewConfigProvider()
+	loader := file.NewFileLoader(provider)
+
+	if err := loader.LoadFromDirectory(dir); err != nil {
+		t.Fatalf("LoadFromDirectory error: %v", err)
+	}
+
+	if got := provider.GetKey("app.port"); got != float64(9090) {
+		t.Errorf("app.port = %v, want 9090 from the later file", got)
+	}
+
+	want := contract.Origin{Layer: contract.LayerFiles, File: dbFile, Line: 3}
+	if origin, ok := loader.Origin("app.port"); !ok || origin != want {
+		t.Errorf("Origin(app.port) = %+v, want %+v", origin, want)
+	}
+
+	want = contract.Origin{Layer: contract.LayerFiles, File: appFile, Line: 2}
+	if origin, ok := loader.Origin("app.name"); !ok || origin != want {
+		t.Errorf("Origin(app.name) = %+v, want %+v", origin, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "extra.yml"), []byte("cache:\n  ttl: 60\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload error: %v", err)
+	}
+
+	if got := provider.GetKey("cache.ttl"); got != 60 {
+		t.Errorf("cache.ttl = %v, want 60 after reload", got)
+	}
+
+	if got := provider.GetKey("app.name"); got != "scg" {
+		t.Errorf("app.name = %v, want scg after reload", got)
+	}
+}
+
+func TestFileLoader_ReloadIsAtomic(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	aFile := filepath.Join(dir, "a.yaml")
+	bFile := filepath.Join(dir, "b.yaml")
+
+	if err := os.WriteFile(aFile, []byte("a: 1\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := os.WriteFile(bFile, []byte("b: 1\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	provider := viper.NewConfigProvider()
+	loader := file.NewFileLoader(provider)
+
+	if err := loader.LoadFromDirectory(dir); err != nil {
+		t.Fatalf("LoadFromDirectory error: %v", err)
+	}
+
+	provider.Set("o", 1)
+
+	// a.yaml parses, b.yaml does not: the provider must keep both old values.
+	if err := os.WriteFile(aFile, []byte("a: 2\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := os.WriteFile(bFile, []byte("b: [1\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := loader.Reload(); !errors2.Is(err, errors.ErrParseConfigFileFailed) {
+		t.Fatalf("Reload error = %v, want ErrParseConfigFileFailed", err)
+	}
+
+	if got := provider.GetKey("a"); got != 1 {
+		t.Errorf("a = %v, want 1 after a failed reload", got)
+	}
+
+	if got := provider.GetKey("b"); got != 1 {
+		t.Errorf("b = %v, want 1 after a failed reload", got)
+	}
+
+	if err := os.Remove(bFile); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload error: %v", err)
+	}
+
+	if got := provider.GetKey("a"); got != 2 {
+		t.Errorf("a = %v, want 2 after reload", got)
+	}
+
+	if provider.IsSet("b") {
+		t.Errorf("b is still set after its file was removed")
+	}
+
+	if got := provider.GetKey("o"); got != 1 {
+		t.Errorf("o = %v, want 1: values set with Set survive a reload", got)
+	}
+}
+
+func TestFileLoader_StageReload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	appFile := filepath.Join(dir, "app.yaml")
+	dbFile := filepath.Join(dir, "db.yaml")
+
+	if err := os.WriteFile(appFile, []byte("app:\n  name: v1\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := os.WriteFile(dbFile, []byte("db:\n  host: h1\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	provider := viper.NewConfigProvider()
+	loader := file.NewFileLoader(provider)
+
+	if err := loader.LoadFromDirectory(dir); err != nil {
+		t.Fatalf("LoadFromDirectory error: %v", err)
+	}
+
+	// A broken second file fails the stage and leaves the loader untouched.
+	if err := os.WriteFile(appFile, []byte("app:\n  name: v2\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := os.WriteFile(dbFile, []byte("db: [h2\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := loader.StageReload(); !errors2.Is(err, errors.ErrParseConfigFileFailed) {
+		t.Fatalf("StageReload error = %v, want ErrParseConfigFileFailed", err)
+	}
+
+	if got := loader.GetProvider().GetKey("app.name"); got != "v1" {
+		t.Errorf("app.name = %v, want v1 after a failed stage", got)
+	}
+
+	if err := os.WriteFile(dbFile, []byte("db:\n  host: h2\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	stage, err := loader.StageReload()
+	if err != nil {
+		t.Fatalf("StageReload error: %v", err)
+	}
+
+	if got := stage.Provider().GetKey("db.host"); got != "h2" {
+		t.Errorf("staged db.host = %v, want h2", got)
+	}
+
+	if got := loader.GetProvider().GetKey("db.host"); got != "h1" {
+		t.Errorf("db.host = %v, want h1 before commit", got)
+	}
+
+	stage.Commit()
+
+	if got := loader.GetProvider().GetKey("db.host"); got != "h2" {
+		t.Errorf("db.host = %v, want h2 after commit", got)
+	}
+
+	if origin, ok := loader.Origin("db.host"); !ok || origin.File != dbFile {
+		t.Errorf("Origin(db.host) = %+v, want %s", origin, dbFile)
+	}
+}
+
+func TestFileLoader_LoadFromDirectory_MixedFormats(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":           "APP_NAME=from-env\nAPP_DEBUG=true\n",
+		"a.toml":         "[app]\nname = \"from-toml\"\nport = 8080\n",
+		"b.hcl":          "database {\n  host = \"db\"\n  port = 5432\n}\n",
+		"c.ini":          "[cache]\nttl = 60\n",
+		"d.properties":   "app.name = from-properties\n",
+		"notes.txt":      "not = config\n",
+		"unsupported.md": "# not config\n",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	provider := viper.NewConfigProvider()
+	loader := file.NewFileLoader(provider)
+
+	if err := loader.LoadFromDirectory(dir); err != nil {
+		t.Fatalf("LoadFromDirectory error: %v", err)
+	}
+
+	want := map[string]any{
+		"app.name":      "from-properties", // d.properties is merged last
+		"app.debug":     "true",            // only set by .env, which is merged first
+		"app.port":      int64(8080),
+		"database.host": "db",
+		"database.port": 5432,
+		"cache.ttl":     "60",
+	}
+
+	for key, value := range want {
+		if got := provider.GetKey(key); got != value {
+			t.Errorf("%s = %#v, want %#v", key, got, value)
+		}
+	}
+
+	if provider.IsSet("not") {
+		t.Error("unsupported files must be skipped")
+	}
+}
+
+func TestFileLoader_LoadFromDirectory_NamespaceByFilename(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"database.json":         `{"host": "db", "timeout": "5s"}`,
+		"cache.yaml":            "timeout: 1s\n",
+		".env":                  "APP_NAME=scg\n",
+		"services/billing.yaml": "url: http://billing\nretry:\n  max: 3\n",
+		"notes.txt":             "not = config\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("mkdir %s: %v", name, err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	provider := viper.NewConfigProvider()
+	loader := file.NewFileLoader(provider, file.WithNamespaceByFilename())
+
+	if err := loader.LoadFromDirectory(dir); err != nil {
+		t.Fatalf("LoadFromDirectory error: %v", err)
+	}
+
+	want := map[string]any{
+		"database.host":              "db",
+		"database.timeout":           "5s",
+		"cache.timeout":              "1s",
+		"app.name":                   "scg",
+		"services.billing.url":       "http://billing",
+		"services.billing.retry.max": 3,
+	}
+
+	for key, value := range want {
+		if got := provider.GetKey(key); got != value {
+			t.Errorf("%s = %#v, want %#v", key, got, value)
+		}
+	}
+
+	if provider.IsSet("timeout") {
+		t.Error("namespaced keys must not be set at the root")
+	}
+
+	origin, ok := loader.Origin("services.billing.retry.max")
+	if !ok {
+		t.Fatal("no origin recorded for services.billing.retry.max")
+	}
+
+	if origin.File != filepath.Join(dir, "services", "billing.yaml") || origin.Line != 3 {
+		t.Errorf("origin = %s:%d, want billing.yaml:3", origin.File, origin.Line)
+	}
+}
+
+func TestFileLoader_LoadFromDirectory_MergesAtRootByDefault(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "database.yaml"), []byte("timeout: 5s\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "services"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "services", "billing.yaml"), []byte("url: x\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	provider := viper.NewConfigProvider()
+	if err := file.NewFileLoader(provider).LoadFromDirectory(dir); err != nil {
+		t.Fatalf("LoadFromDirectory error: %v", err)
+	}
+
+	if got := provider.GetKey("timeout"); got != "5s" {
+		t.Errorf("timeout = %#v, want \"5s\"", got)
+	}
+
+	if provider.IsSet("url") || provider.IsSet("services.billing.url") {
+		t.Error("subdirectories must not be loaded without WithNamespaceByFilename")
+	}
+}
+
+// writeTree writes files, keyed by slash-separated paths relative to dir.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("mkdir %s: %v", name, err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+func TestFileLoader_LoadFromDirectory_Recursive(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"base.yaml":                     "level: root\nroot: true\n",
+		"billing/service.yaml":          "level: billing\nbilling: true\n",
+		"billing/service.example.yaml":  "level: example\n",
+		"billing/zz/deeper.yaml":        "level: deeper\n",
+		"_drafts/draft.yaml":            "level: draft\n",
+		"z.yaml":                        "z: true\n",
+		".git/config.yaml":              "level: git\n",
+		"legacy/old.yaml":               "level: legacy\n",
+		"legacy/keep/also-skipped.yaml": "level: legacy\n",
+	})
+
+	provider := viper.NewConfigProvider()
+	loader := file.NewFileLoader(provider,
+		file.WithRecursive(),
+		file.WithExclude("*.example.yaml", "_*", "legacy"),
+	)
+
+	if err := loader.LoadFromDirectory(dir); err != nil {
+		t.Fatalf("LoadFromDirectory error: %v", err)
+	}
+
+	// A directory's own files first, then its subdirectories, depth first.
+	if got := provider.GetKey("level"); got != "deeper" {
+		t.Errorf("level = %#v, want \"deeper\"", got)
+	}
+
+	for _, key := range []string{"root", "billing", "z"} {
+		if provider.GetKey(key) != true {
+			t.Errorf("%s was not loaded", key)
+		}
+	}
+
+	origin, _ := loader.Origin("level")
+	if origin.File != filepath.Join(dir, "billing", "zz", "deeper.yaml") {
+		t.Errorf("level origin = %s, want billing/zz/deeper.yaml", origin.File)
+	}
+}
+
+func TestFileLoader_LoadFromDirectory_Include(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"app.yaml":            "app: true\n",
+		"app.json":            `{"json": true}`,
+		"services/api.yaml":   "api: true\n",
+		"services/local.yaml": "local: true\n",
+	})
+
+	provider := viper.NewConfigProvider()
+	loader := file.NewFileLoader(provider,
+		file.WithRecursive(),
+		file.WithInclude("*.yaml"),
+		file.WithExclude("services/local.yaml"),
+	)
+
+	if err := loader.LoadFromDirectory(dir); err != nil {
+		t.Fatalf("LoadFromDirectory error: %v", err)
+	}
+
+	for key, want := range map[string]bool{"app": true, "api": true, "json": false, "local": false} {
+		if got := provider.IsSet(key); got != want {
+			t.Errorf("IsSet(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestFileLoader_LoadFromDirectory_InvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	loader := file.NewFileLoader(viper.NewConfigProvider(), file.WithExclude("[z-a"))
+
+	err := loader.LoadFromDirectory(t.TempDir())
+	if !errors2.Is(err, errors.ErrInvalidGlobPattern) {
+		t.Errorf("expected ErrInvalidGlobPattern, got %v", err)
+	}
+}
+
+func TestFileLoader_ListDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"app.yaml":                  "a: 1\n",
+		"app.example.yaml":          "a: 2\n",
+		"services/billing.yaml":     "b: 1\n",
+		"services/empty/README.txt": "",
+	})
+
+	loader := file.NewFileLoader(viper.NewConfigProvider(), file.WithRecursive(), file.WithExclude("*.example.yaml"))
+
+	files, dirs, err := loader.ListDirectory(dir)
+	if err != nil {
+		t.Fatalf("ListDirectory error: %v", err)
+	}
+
+	wantFiles := []string{filepath.Join(dir, "app.yaml"), filepath.Join(dir, "services", "billing.yaml")}
+	if !slices.Equal(files, wantFiles) {
+		t.Errorf("files = %v, want %v", files, wantFiles)
+	}
+
+	wantDirs := []string{dir, filepath.Join(dir, "services"), filepath.Join(dir, "services", "empty")}
+	if !slices.Equal(dirs, wantDirs) {
+		t.Errorf("dirs = %v, want %v", dirs, wantDirs)
+	}
+}
+
+func TestFileLoader_LoadFromDirectory_Symlinks(t *testing.T) {
+	t.Parallel()
+
+	shared := t.TempDir()
+	writeTree(t, shared, map[string]string{"shared.yaml": "shared: true\n"})
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"app.yaml": "app: true\n"})
+
+	if err := os.Symlink(filepath.Join(shared, "shared.yaml"), filepath.Join(dir, "linked.yaml")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	if err := os.Symlink(shared, filepath.Join(dir, "component")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	// A link back to the root must not make the walk loop.
+	if err := os.Symlink(dir, filepath.Join(dir, "loop")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	if err := os.Symlink(filepath.Join(dir, "missing.yaml"), filepath.Join(dir, "dangling.yaml")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		policy file.SymlinkPolicy
+		want   map[string]bool
+	}{
+		{name: "files", policy: file.SymlinkFiles, want: map[string]bool{"app": true, "shared": true}},
+		{name: "follow", policy: file.SymlinkFollow, want: map[string]bool{"app": true, "shared": true}},
+		{name: "ignore", policy: file.SymlinkIgnore, want: map[string]bool{"app": true, "shared": false}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider := viper.NewConfigProvider()
+			loader := file.NewFileLoader(provider, file.WithRecursive(), file.WithSymlinks(testCase.policy))
+
+			if err := loader.LoadFromDirectory(dir); err != nil {
+				t.Fatalf("LoadFromDirectory error: %v", err)
+			}
+
+			for key, want := range testCase.want {
+				if got := provider.IsSet(key); got != want {
+					t.Errorf("IsSet(%q) = %v, want %v", key, got, want)
+				}
+			}
+
+			origin, _ := loader.Origin("shared")
+			followed := origin.File == filepath.Join(dir, "component", "shared.yaml")
+
+			if followed != (testCase.policy == file.SymlinkFollow) {
+				t.Errorf("shared origin = %q with policy %s", origin.File, testCase.name)
+			}
+		})
+	}
+}
+
+func TestFileLoader_LoadProfile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"app.yaml":            "app:\n  name: base\n  port: 8080\n  debug: false\n",
+		"app.staging.yaml":    "app:\n  name: staging\n  port: 9090\n",
+		"app.production.yaml": "app:\n  name: production\n",
+		"app.local.yaml":      "app:\n  debug: true\n  port: 7070\n",
+		"db.local.yaml":       "db:\n  host: localhost\n",
+	})
+
+	provider := viper.NewConfigProvider()
+	loader := file.NewFileLoader(provider)
+
+	if err := loader.LoadProfile(dir, "staging", "Local"); err != nil {
+		t.Fatalf("LoadProfile error: %v", err)
+	}
+
+	want := map[string]any{
+		"app.name":  "staging",
+		"app.port":  7070,
+		"app.debug": true,
+		"db.host":   "localhost",
+	}
+
+	for key, value := range want {
+		if got := provider.GetKey(key); got != value {
+			t.Errorf("%s = %#v, want %#v", key, got, value)
+		}
+	}
+
+	wantProfiles := map[string]string{"app.name": "staging", "app.port": "local", "app.debug": "local"}
+	for key, profile := range wantProfiles {
+		if origin, _ := loader.Origin(key); origin.Profile != profile {
+			t.Errorf("%s origin profile = %q, want %q", key, origin.Profile, profile)
+		}
+	}
+
+	// Without profiles only the base files are loaded.
+	base := viper.NewConfigProvider()
+	if err := file.NewFileLoader(base).LoadProfile(dir); err != nil {
+		t.Fatalf("LoadProfile error: %v", err)
+	}
+
+	if got := base.GetKey("app.name"); got != "base" {
+		t.Errorf("app.name = %#v, want \"base\"", got)
+	}
+
+	if base.IsSet("db.host") {
+		t.Error("overlays must not be loaded without profiles")
+	}
+}
+
+func TestFileLoader_LoadProfile_NamespaceByFilename(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"database.yaml":         "host: db\nport: 5432\n",
+		"database.staging.yaml": "host: staging-db\n",
+	})
+
+	provider := viper.NewConfigProvider()
+	if err := file.NewFileLoader(provider, file.WithNamespaceByFilename()).LoadProfile(dir, "staging"); err != nil {
+		t.Fatalf("LoadProfile error: %v", err)
+	}
+
+	if got := provider.GetKey("database.host"); got != "staging-db" {
+		t.Errorf("database.host = %#v, want \"staging-db\"", got)
+	}
+
+	if got := provider.GetKey("database.port"); got != 5432 {
+		t.Errorf("database.port = %#v, want 5432", got)
+	}
+}
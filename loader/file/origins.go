@@ -0,0 +1,82 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/dotmap"
+)
+
+// recordOrigins records file in origins as the origin of every key configMap sets
+// under the file's namespace. Keys are lower-cased like the provider does, since custom
+// decoders may keep case.
+func recordOrigins(origins map[string]contract.Origin, file configFile, configMap map[string]any) {
+	lines := lineNumbers(file.path)
+
+	for key := range dotmap.Flatten(configMap) {
+		key = strings.ToLower(key)
+
+		path := key
+		if file.namespace != "" {
+			path = file.namespace + "." + key
+		}
+
+		origins[path] = contract.Origin{
+			Layer:   contract.LayerFiles,
+			File:    file.path,
+			Line:    lines[key],
+			EnvVar:  "",
+			Flag:    "",
+			Profile: file.profile,
+		}
+	}
+}
+
+// lineNumbers maps each dotted key of a YAML or JSON file to the line that defines it.
+// Other formats, and files that fail to parse, report no lines.
+func lineNumbers(configFile string) map[string]int {
+	switch strings.ToLower(filepath.Ext(configFile)) {
+	case contract.ExtYAML, contract.ExtYML, contract.ExtJSON:
+	default:
+		return nil
+	}
+
+	data, err := os.ReadFile(configFile) // #nosec G304 -- path comes from the caller's own config sources
+	if err != nil {
+		return nil
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return nil
+	}
+
+	lines := make(map[string]int)
+	collectLines(lines, "", root.Content[0])
+
+	return lines
+}
+
+// collectLines walks mapping nodes and records the line of every key under prefix.
+// Keys are lower-cased to match the provider's normalised keys.
+func collectLines(lines map[string]int, prefix string, node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		keyNode, valueNode := node.Content[idx], node.Content[idx+1]
+
+		path := strings.ToLower(keyNode.Value)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		lines[path] = keyNode.Line
+		collectLines(lines, path, valueNode)
+	}
+}
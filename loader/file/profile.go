@@ -0,0 +1,64 @@
+package file
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// profileFiles picks the base files and the overlays of profiles out of files, in
+// merge order: every base file first, then the overlays of each profile in turn.
+func profileFiles(files []configFile, profiles []string) []configFile {
+	overlays := make(map[string][]configFile, len(profiles))
+
+	var merged []configFile
+
+	for _, file := range files {
+		profile, isOverlay := overlayProfile(file.path)
+		if !isOverlay {
+			merged = append(merged, file)
+
+			continue
+		}
+
+		// app.staging.yaml is namespaced like app.yaml when namespacing by filename.
+		file.namespace = strings.TrimSuffix(file.namespace, "."+profile)
+		file.profile = profile
+		overlays[profile] = append(overlays[profile], file)
+	}
+
+	for _, profile := range profiles {
+		merged = append(merged, overlays[profile]...)
+	}
+
+	return merged
+}
+
+// overlayProfile returns the profile of an overlay such as app.staging.yaml. Files
+// without a dot before the extension are base files.
+func overlayProfile(path string) (string, bool) {
+	name := filepath.Base(path)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	idx := strings.LastIndex(name, ".")
+	if idx <= 0 {
+		return "", false
+	}
+
+	return strings.ToLower(name[idx+1:]), true
+}
+
+// normalizeProfiles lower-cases profiles and drops empty and repeated ones. The result
+// is never nil, so that a profile source with no profiles still skips every overlay.
+func normalizeProfiles(profiles []string) []string {
+	normalized := make([]string, 0, len(profiles))
+
+	for _, profile := range profiles {
+		profile = strings.ToLower(strings.TrimSpace(profile))
+		if profile != "" && !slices.Contains(normalized, profile) {
+			normalized = append(normalized, profile)
+		}
+	}
+
+	return normalized
+}
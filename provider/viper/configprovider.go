@@ -1,21 +1,26 @@
 package viper
 
 import (
+	"bytes"
+	errors2 "errors"
 	"fmt"
 
 	"github.com/spf13/viper"
 
 	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
 )
 
 // ConfigProvider implements contract.Provider using Viper.
 type ConfigProvider struct {
 	v *viper.Viper
+	// overrides holds the values set with Set, so that Clone can copy them as such
+	overrides *viper.Viper
 }
 
 // NewConfigProvider returns a new ConfigProvider instance (satisfies contract.Provider).
 func NewConfigProvider() *ConfigProvider {
-	return &ConfigProvider{v: viper.New()}
+	return &ConfigProvider{v: viper.New(), overrides: viper.New()}
 }
 
 // AllSettings returns the entire config as a nested map.
@@ -36,11 +41,36 @@ func (b *ConfigProvider) IsSet(key string) bool {
 // Set sets a key in the Viper store (for tests or live editing).
 func (b *ConfigProvider) Set(key string, value any) {
 	b.v.Set(key, value)
+	b.overrides.Set(key, value)
 }
 
-// ReadInConfig reloads from file/env if supported by Viper.
+// Clone returns a copy of the provider: its settings become the file config of the copy,
+// and the values set with Set are set on the copy as well, so that they survive a
+// ReplaceConfig of the copy as they would on the original.
+//
+//nolint:ireturn // returning an interface is required by the contract API
+func (b *ConfigProvider) Clone() (contract.Provider, error) {
+	clone := NewConfigProvider()
+	if err := clone.MergeConfigMap(b.v.AllSettings()); err != nil {
+		return nil, err
+	}
+
+	for key, value := range b.overrides.AllSettings() {
+		clone.Set(key, value)
+	}
+
+	return clone, nil
+}
+
+// ReadInConfig reloads from file/env if supported by Viper. A file that exists but
+// cannot be parsed is reported as errors.ErrParseConfigFileFailed.
 func (b *ConfigProvider) ReadInConfig() error {
 	if err := b.v.ReadInConfig(); err != nil {
+		var parseErr viper.ConfigParseError
+		if errors2.As(err, &parseErr) {
+			return fmt.Errorf("provider: %w: %w", errors.ErrParseConfigFileFailed, err)
+		}
+
 		return fmt.Errorf("provider: failed to read config: %w", err)
 	}
 
@@ -52,6 +82,12 @@ func (b *ConfigProvider) SetConfigFile(file string) {
 	b.v.SetConfigFile(file)
 }
 
+// ConfigFileUsed returns the file ReadInConfig reads, or "" if none has been set or
+// found yet.
+func (b *ConfigProvider) ConfigFileUsed() string {
+	return b.v.ConfigFileUsed()
+}
+
 // MergeConfigMap merges another map into config.
 func (b *ConfigProvider) MergeConfigMap(cfg map[string]interface{}) error {
 	if err := b.v.MergeConfigMap(cfg); err != nil {
@@ -61,10 +97,30 @@ func (b *ConfigProvider) MergeConfigMap(cfg map[string]interface{}) error {
 	return nil
 }
 
+// ReplaceConfig replaces the values read from config files with settings, as if they had
+// been read from a single file. Values set with Set are kept.
+func (b *ConfigProvider) ReplaceConfig(settings map[string]interface{}) error {
+	b.v.SetConfigType("json")
+	defer b.v.SetConfigType("")
+
+	if err := b.v.ReadConfig(bytes.NewReader([]byte("{}"))); err != nil {
+		return fmt.Errorf("provider: failed to reset config: %w", err)
+	}
+
+	if err := b.v.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("provider: failed to replace config: %w", err)
+	}
+
+	return nil
+}
+
 // Provider returns the underlying Viper object for advanced use.
 func (b *ConfigProvider) Provider() any {
 	return b.v
 }
 
 // Interface assertion: this struct implements contract.Provider.
-var _ contract.Provider = (*ConfigProvider)(nil)
+var (
+	_ contract.Provider = (*ConfigProvider)(nil)
+	_ contract.Cloner   = (*ConfigProvider)(nil)
+)
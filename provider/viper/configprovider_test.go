@@ -56,3 +56,46 @@ func TestConfigProvider_ConfigFile(t *testing.T) {
 		t.Errorf("unexpected config: foo=%v num=%v", provider.GetKey(testKey), provider.GetKey("num"))
 	}
 }
+
+func TestConfigProvider_Clone(t *testing.T) {
+	t.Parallel()
+
+	provider := viper.NewConfigProvider()
+	if err := provider.MergeConfigMap(map[string]any{"file": 1, testKey: "file"}); err != nil {
+		t.Fatalf("MergeConfigMap error: %v", err)
+	}
+
+	provider.Set(testKey, testValue)
+
+	cloned, err := provider.Clone()
+	if err != nil {
+		t.Fatalf("Clone error: %v", err)
+	}
+
+	clone, ok := cloned.(*viper.ConfigProvider)
+	if !ok {
+		t.Fatalf("Clone returned %T, want *viper.ConfigProvider", cloned)
+	}
+
+	if err := clone.ReplaceConfig(map[string]any{"other": 2}); err != nil {
+		t.Fatalf("ReplaceConfig error: %v", err)
+	}
+
+	clone.Set("extra", testNum)
+
+	if v := clone.GetKey(testKey); v != testValue {
+		t.Errorf("clone %s = %v, want the value set with Set to survive ReplaceConfig", testKey, v)
+	}
+
+	if clone.IsSet("file") {
+		t.Errorf("clone kept file after ReplaceConfig")
+	}
+
+	if v := provider.GetKey("file"); v != 1 {
+		t.Errorf("file = %v, want 1: changing the clone must not change the original", v)
+	}
+
+	if provider.IsSet("extra") || provider.IsSet("other") {
+		t.Errorf("values set on the clone leaked into the original")
+	}
+}
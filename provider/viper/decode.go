@@ -0,0 +1,30 @@
+package viper
+
+import (
+	"bytes"
+	errors2 "errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/hbttundar/scg-config/errors"
+)
+
+// Decode parses data with the Viper decoder for configType ("yaml", "toml", "dotenv",
+// ...) and returns the nested settings with lower-cased keys. Data that is not valid for
+// the format is reported as errors.ErrParseConfigFileFailed.
+func Decode(configType string, data []byte) (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigType(configType)
+
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		var parseErr viper.ConfigParseError
+		if errors2.As(err, &parseErr) {
+			return nil, fmt.Errorf("provider: %w: %w", errors.ErrParseConfigFileFailed, err)
+		}
+
+		return nil, fmt.Errorf("provider: failed to decode %s config: %w", configType, err)
+	}
+
+	return v.AllSettings(), nil
+}
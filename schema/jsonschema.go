@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+)
+
+// jsonSchema is the subset of JSON Schema understood by LoadJSONSchema.
+type jsonSchema struct {
+	Type       string                 `json:"type"`
+	Format     string                 `json:"format"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Required   []string               `json:"required"`
+	Minimum    *float64               `json:"minimum"`
+	Maximum    *float64               `json:"maximum"`
+	Enum       []any                  `json:"enum"`
+	Pattern    string                 `json:"pattern"`
+	MinLength  *int                   `json:"minLength"`
+	MaxLength  *int                   `json:"maxLength"`
+	MinItems   *int                   `json:"minItems"`
+	MaxItems   *int                   `json:"maxItems"`
+}
+
+// keyTypesByJSONType maps JSON Schema types to KeyTypes.
+//
+//nolint:gochecknoglobals // static lookup table
+var keyTypesByJSONType = map[string]contract.KeyType{
+	"string":  contract.String,
+	"integer": contract.Int64,
+	"number":  contract.Float64,
+	"boolean": contract.Bool,
+	"object":  contract.Map,
+}
+
+// keyTypesByFormat maps JSON Schema string formats to KeyTypes.
+//
+//nolint:gochecknoglobals // static lookup table
+var keyTypesByFormat = map[string]contract.KeyType{
+	"duration":  contract.Duration,
+	"date-time": contract.Time,
+	"uri":       contract.URL,
+	"uuid":      contract.UUID,
+}
+
+// LoadJSONSchema reads a JSON Schema document from disk.
+// See ParseJSONSchema for the supported keywords.
+func LoadJSONSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- schema path is chosen by the caller
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errors.ErrInvalidSchema, err)
+	}
+
+	return ParseJSONSchema(data)
+}
+
+// ParseJSONSchema builds a Schema from a JSON Schema document describing the config
+// object. Nested "properties" become dotted keys. Supported keywords are type, format
+// (duration, date-time, uri, uuid), properties, required, minimum, maximum, enum,
+// pattern, minLength, maxLength, minItems and maxItems.
+func ParseJSONSchema(data []byte) (*Schema, error) {
+	var root jsonSchema
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%w: %w", errors.ErrInvalidSchema, err)
+	}
+
+	schema := New()
+	if err := schema.addJSONProperties("", &root); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// addJSONProperties adds a field for every property of node, recursing into objects.
+func (s *Schema) addJSONProperties(prefix string, node *jsonSchema) error {
+	for _, name := range slices.Sorted(maps.Keys(node.Properties)) {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		prop := node.Properties[name]
+
+		field := s.Field(key)
+		field.scope = prefix
+
+		if slices.Contains(node.Required, name) {
+			field.Required()
+		}
+
+		if err := field.applyJSON(prop); err != nil {
+			return fmt.Errorf("%w: %s: %w", errors.ErrInvalidSchema, key, err)
+		}
+
+		if len(prop.Properties) > 0 {
+			if err := s.addJSONProperties(key, prop); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range node.Required {
+		if _, ok := node.Properties[name]; !ok {
+			key := name
+			if prefix != "" {
+				key = prefix + "." + name
+			}
+
+			field := s.Field(key)
+			field.scope = prefix
+			field.Required()
+		}
+	}
+
+	return nil
+}
+
+// applyJSON copies the keyword constraints of prop onto the field.
+func (f *Field) applyJSON(prop *jsonSchema) error {
+	switch {
+	case prop.Format != "" && keyTypesByFormat[prop.Format] != "":
+		f.Type(keyTypesByFormat[prop.Format])
+	case prop.Type == "array":
+		f.kind = reflect.Slice
+	case prop.Type != "":
+		typ, ok := keyTypesByJSONType[prop.Type]
+		if !ok {
+			return fmt.Errorf("unsupported type %q", prop.Type)
+		}
+
+		f.Type(typ)
+	}
+
+	f.min, f.max = prop.Minimum, prop.Maximum
+	f.enum = prop.Enum
+	f.minLen, f.maxLen = prop.MinLength, prop.MaxLength
+
+	if prop.MinItems != nil {
+		f.minLen = prop.MinItems
+	}
+
+	if prop.MaxItems != nil {
+		f.maxLen = prop.MaxItems
+	}
+
+	if prop.Pattern != "" {
+		pattern, err := regexp.Compile(prop.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+
+		f.pattern = pattern
+	}
+
+	return nil
+}
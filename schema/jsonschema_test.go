@@ -0,0 +1,85 @@
+package schema_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/schema"
+)
+
+const jsonSchemaDoc = `{
+  "type": "object",
+  "required": ["server"],
+  "properties": {
+    "server": {
+      "type": "object",
+      "required": ["port"],
+      "properties": {
+        "port": {"type": "integer", "minimum": 1, "maximum": 65535},
+        "timeout": {"type": "string", "format": "duration"},
+        "mode": {"enum": ["http", "https"]}
+      }
+    },
+    "cache": {
+      "type": "object",
+      "required": ["url"],
+      "properties": {
+        "url": {"type": "string", "pattern": "^redis://"}
+      }
+    },
+    "hosts": {"type": "array", "minItems": 1}
+  }
+}`
+
+func TestLoadJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(path, []byte(jsonSchemaDoc), 0o600))
+
+	s, err := schema.LoadJSONSchema(path)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Validate(map[string]any{
+		"server": map[string]any{"port": 8080, "timeout": "5s", "mode": "http"},
+		"hosts":  []any{"a"},
+	}))
+
+	err = s.Validate(map[string]any{
+		"server": map[string]any{"timeout": "soon", "mode": "ftp"},
+		"cache":  map[string]any{},
+		"hosts":  "a",
+	})
+
+	var validationErr *schema.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ElementsMatch(t, []schema.Violation{
+		{Key: "cache.url", Message: "is required"},
+		{Key: "hosts", Message: "must be a slice"},
+		{Key: "server.port", Message: "is required"},
+		{Key: "server.timeout", Message: "must be of type duration"},
+		{Key: "server.mode", Message: "must be one of [http https]"},
+	}, validationErr.Violations)
+
+	err = s.Validate(map[string]any{})
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []schema.Violation{{Key: "server", Message: "is required"}}, validationErr.Violations)
+}
+
+func TestLoadJSONSchema_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := schema.LoadJSONSchema(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorIs(t, err, errors.ErrInvalidSchema)
+
+	_, err = schema.ParseJSONSchema([]byte(`{"properties": {"a": {"type": "tuple"}}}`))
+	require.ErrorIs(t, err, errors.ErrInvalidSchema)
+
+	_, err = schema.ParseJSONSchema([]byte(`{"properties": {"a": {"pattern": "("}}}`))
+	require.ErrorIs(t, err, errors.ErrInvalidSchema)
+}
@@ -0,0 +1,351 @@
+// Package schema provides declarative validation of a loaded configuration.
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/utils"
+)
+
+// Violation is a single problem found while validating a key.
+type Violation struct {
+	Key     string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Key == "" {
+		return v.Message
+	}
+
+	return v.Key + ": " + v.Message
+}
+
+// ValidationError lists every violation found in one validation run.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for idx, violation := range e.Violations {
+		parts[idx] = violation.String()
+	}
+
+	return fmt.Sprintf("%s: %s", errors.ErrValidationFailed, strings.Join(parts, "; "))
+}
+
+// Is makes errors.Is(err, errors.ErrValidationFailed) match.
+func (e *ValidationError) Is(target error) bool {
+	return target == errors.ErrValidationFailed //nolint:errorlint // identity check against the sentinel is intended
+}
+
+// RuleFunc is a cross-field check over the whole configuration.
+type RuleFunc func(values contract.ValueAccessor) error
+
+type rule struct {
+	name  string
+	check RuleFunc
+}
+
+// Schema is a set of field constraints and cross-field rules.
+type Schema struct {
+	fields []*Field
+	rules  []rule
+}
+
+// New returns an empty Schema.
+func New() *Schema {
+	return &Schema{fields: nil, rules: nil}
+}
+
+// Field returns the constraints for key, creating them on first use.
+func (s *Schema) Field(key string) *Field {
+	for _, field := range s.fields {
+		if field.key == key {
+			return field
+		}
+	}
+
+	field := &Field{key: key}
+	s.fields = append(s.fields, field)
+
+	return field
+}
+
+// Keys returns the key of every field, in the order they were declared.
+func (s *Schema) Keys() []string {
+	keys := make([]string, len(s.fields))
+	for idx, field := range s.fields {
+		keys[idx] = field.key
+	}
+
+	return keys
+}
+
+// KeyTypes returns the declared type of every field that has one. Fields declared as
+// arrays by a JSON Schema are reported as contract.StringSlice.
+func (s *Schema) KeyTypes() map[string]contract.KeyType {
+	types := make(map[string]contract.KeyType)
+
+	for _, field := range s.fields {
+		switch {
+		case field.typ != "":
+			types[field.key] = field.typ
+		case field.kind == reflect.Slice:
+			types[field.key] = contract.StringSlice
+		}
+	}
+
+	return types
+}
+
+// Rule adds a named cross-field rule. A non-nil error from check becomes a violation.
+func (s *Schema) Rule(name string, check RuleFunc) *Schema {
+	s.rules = append(s.rules, rule{name: name, check: check})
+
+	return s
+}
+
+// Validate checks settings against every field and rule and returns a *ValidationError
+// listing all violations, or nil.
+func (s *Schema) Validate(settings map[string]any) error {
+	getter := config.NewGetter(settings)
+
+	var violations []Violation
+
+	for _, field := range s.fields {
+		violations = append(violations, field.validate(getter)...)
+	}
+
+	for _, r := range s.rules {
+		if err := r.check(getter); err != nil {
+			violations = append(violations, Violation{Key: r.name, Message: err.Error()})
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Violations: violations}
+}
+
+// Field holds the constraints for one dotted key.
+type Field struct {
+	key      string
+	scope    string // required only when this parent key is present
+	required bool
+	typ      contract.KeyType
+	kind     reflect.Kind
+	min, max *float64
+	enum     []any
+	pattern  *regexp.Regexp
+	minLen   *int
+	maxLen   *int
+}
+
+// Required marks the key as mandatory.
+func (f *Field) Required() *Field {
+	f.required = true
+
+	return f
+}
+
+// Type requires the value to be convertible to typ.
+func (f *Field) Type(typ contract.KeyType) *Field {
+	f.typ = typ
+
+	return f
+}
+
+// Min requires a numeric value of at least minimum.
+func (f *Field) Min(minimum float64) *Field {
+	f.min = &minimum
+
+	return f
+}
+
+// Max requires a numeric value of at most maximum.
+func (f *Field) Max(maximum float64) *Field {
+	f.max = &maximum
+
+	return f
+}
+
+// Enum requires the value to equal one of values. Numbers are compared by value.
+func (f *Field) Enum(values ...any) *Field {
+	f.enum = values
+
+	return f
+}
+
+// Pattern requires a string value matching the regular expression. It panics if the
+// expression does not compile, like regexp.MustCompile.
+func (f *Field) Pattern(expr string) *Field {
+	f.pattern = regexp.MustCompile(expr)
+
+	return f
+}
+
+// MinLen requires a string, slice or map of at least n characters or elements.
+func (f *Field) MinLen(n int) *Field {
+	f.minLen = &n
+
+	return f
+}
+
+// MaxLen requires a string, slice or map of at most n characters or elements.
+func (f *Field) MaxLen(n int) *Field {
+	f.maxLen = &n
+
+	return f
+}
+
+// validate checks the field against the config and returns its violations.
+func (f *Field) validate(getter *config.Getter) []Violation {
+	raw, err := getter.Lookup(f.key)
+	if err != nil {
+		if f.required && (f.scope == "" || getter.HasKey(f.scope)) {
+			return []Violation{f.violation("is required")}
+		}
+
+		return nil
+	}
+
+	if f.typ != "" {
+		if _, err := getter.Get(f.key, f.typ); err != nil {
+			return []Violation{f.violation(fmt.Sprintf("must be of type %s", f.typ))}
+		}
+	}
+
+	if f.kind != reflect.Invalid && reflect.ValueOf(raw).Kind() != f.kind {
+		return []Violation{f.violation(fmt.Sprintf("must be a %s", f.kind))}
+	}
+
+	var violations []Violation
+
+	for _, check := range []func(any) string{f.checkRange, f.checkEnum, f.checkPattern, f.checkLength} {
+		if msg := check(raw); msg != "" {
+			violations = append(violations, f.violation(msg))
+		}
+	}
+
+	return violations
+}
+
+func (f *Field) violation(msg string) Violation {
+	return Violation{Key: f.key, Message: msg}
+}
+
+func (f *Field) checkRange(raw any) string {
+	if f.min == nil && f.max == nil {
+		return ""
+	}
+
+	num, err := utils.ToFloat64(raw)
+	if err != nil {
+		return "must be a number"
+	}
+
+	if f.min != nil && num < *f.min {
+		return fmt.Sprintf("must be >= %v", *f.min)
+	}
+
+	if f.max != nil && num > *f.max {
+		return fmt.Sprintf("must be <= %v", *f.max)
+	}
+
+	return ""
+}
+
+func (f *Field) checkEnum(raw any) string {
+	if len(f.enum) == 0 {
+		return ""
+	}
+
+	if slices.ContainsFunc(f.enum, func(allowed any) bool { return enumEqual(allowed, raw) }) {
+		return ""
+	}
+
+	return fmt.Sprintf("must be one of %v", f.enum)
+}
+
+func (f *Field) checkPattern(raw any) string {
+	if f.pattern == nil {
+		return ""
+	}
+
+	str, ok := raw.(string)
+	if !ok {
+		return "must be a string"
+	}
+
+	if !f.pattern.MatchString(str) {
+		return fmt.Sprintf("must match %q", f.pattern.String())
+	}
+
+	return ""
+}
+
+func (f *Field) checkLength(raw any) string {
+	if f.minLen == nil && f.maxLen == nil {
+		return ""
+	}
+
+	length, ok := valueLength(raw)
+	if !ok {
+		return "must be a string, list or map"
+	}
+
+	if f.minLen != nil && length < *f.minLen {
+		return fmt.Sprintf("length must be >= %d", *f.minLen)
+	}
+
+	if f.maxLen != nil && length > *f.maxLen {
+		return fmt.Sprintf("length must be <= %d", *f.maxLen)
+	}
+
+	return ""
+}
+
+// enumEqual compares numbers by value so that 1 (int) matches 1.0 (float64).
+func enumEqual(allowed, raw any) bool {
+	allowedNum, errAllowed := utils.ToFloat64(allowed)
+	rawNum, errRaw := utils.ToFloat64(raw)
+
+	_, allowedIsString := allowed.(string)
+	_, rawIsString := raw.(string)
+
+	if errAllowed == nil && errRaw == nil && !allowedIsString && !rawIsString {
+		return allowedNum == rawNum
+	}
+
+	return reflect.DeepEqual(allowed, raw)
+}
+
+// valueLength returns the rune count of strings and the length of slices and maps.
+func valueLength(raw any) (int, bool) {
+	if str, ok := raw.(string); ok {
+		return utf8.RuneCountInString(str), true
+	}
+
+	val := reflect.ValueOf(raw)
+	switch val.Kind() { //nolint:exhaustive // only collections have a length
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return val.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+// Compile time checks for interface.
+var _ contract.Validator = (*Schema)(nil)
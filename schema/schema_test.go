@@ -0,0 +1,100 @@
+package schema_test
+
+import (
+	errors2 "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/schema"
+)
+
+func serverSchema() *schema.Schema {
+	s := schema.New()
+	s.Field("server.port").Required().Type(contract.Int).Min(1).Max(65535)
+	s.Field("server.host").Required().Pattern(`^[a-z.-]+$`).MinLen(3).MaxLen(20)
+	s.Field("log.level").Enum("debug", "info", "warn")
+	s.Field("auth.roles").MinLen(1)
+	s.Rule("tls", func(values contract.ValueAccessor) error {
+		if values.Has("tls.cert") != values.Has("tls.key") {
+			return errors2.New("tls.cert and tls.key must be set together")
+		}
+
+		return nil
+	})
+
+	return s
+}
+
+func TestSchema_Valid(t *testing.T) {
+	t.Parallel()
+
+	err := serverSchema().Validate(map[string]any{
+		"server": map[string]any{"port": 8080, "host": "localhost"},
+		"log":    map[string]any{"level": "info"},
+		"auth":   map[string]any{"roles": []any{"admin"}},
+	})
+	require.NoError(t, err)
+}
+
+func TestSchema_CollectsEveryViolation(t *testing.T) {
+	t.Parallel()
+
+	err := serverSchema().Validate(map[string]any{
+		"server": map[string]any{"port": 70000},
+		"log":    map[string]any{"level": "trace"},
+		"auth":   map[string]any{"roles": []any{}},
+		"tls":    map[string]any{"cert": "cert.pem"},
+	})
+	require.ErrorIs(t, err, errors.ErrValidationFailed)
+
+	var validationErr *schema.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []schema.Violation{
+		{Key: "server.port", Message: "must be <= 65535"},
+		{Key: "server.host", Message: "is required"},
+		{Key: "log.level", Message: "must be one of [debug info warn]"},
+		{Key: "auth.roles", Message: "length must be >= 1"},
+		{Key: "tls", Message: "tls.cert and tls.key must be set together"},
+	}, validationErr.Violations)
+	assert.Contains(t, err.Error(), "server.port: must be <= 65535")
+}
+
+func TestSchema_TypeAndPattern(t *testing.T) {
+	t.Parallel()
+
+	err := serverSchema().Validate(map[string]any{
+		"server": map[string]any{"port": "http", "host": "Bad_Host"},
+	})
+
+	var validationErr *schema.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []schema.Violation{
+		{Key: "server.port", Message: "must be of type int"},
+		{Key: "server.host", Message: `must match "^[a-z.-]+$"`},
+	}, validationErr.Violations)
+}
+
+func TestSchema_Keys(t *testing.T) {
+	t.Parallel()
+
+	s := serverSchema()
+	s.Field("server.port").Min(2) // reusing a field does not list it twice
+
+	assert.Equal(t, []string{"server.port", "server.host", "log.level", "auth.roles"}, s.Keys())
+}
+
+func TestSchema_KeyTypes(t *testing.T) {
+	t.Parallel()
+
+	s := serverSchema()
+	s.Field("server.timeout").Type(contract.Duration)
+
+	assert.Equal(t, map[string]contract.KeyType{
+		"server.port":    contract.Int,
+		"server.timeout": contract.Duration,
+	}, s.KeyTypes())
+}
@@ -4,15 +4,15 @@ import (
 	"fmt"
 	"math"
 	"net/url"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
-	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/decoder"
 	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/internal/envkey"
 )
 
 const (
@@ -21,7 +21,7 @@ const (
 
 // NormalizeEnvKey converts an environment variable key (e.g. APP_NAME) to dot notation (e.g. app.name).
 func NormalizeEnvKey(key string) string {
-	return strings.ToLower(strings.ReplaceAll(key, "_", "."))
+	return envkey.Normalize(key)
 }
 
 // NormalizePrefix prepares the prefix for env matching.
@@ -58,14 +58,11 @@ func StripPrefix(key, prefix string) string {
 	return key
 }
 
-// IsSupportedConfigFile returns true if the file has a supported config extension.
+// IsSupportedConfigFile returns true if a decoder is registered for the file's extension.
+//
+// Deprecated: use decoder.Supports, which this calls.
 func IsSupportedConfigFile(filename string) bool {
-	switch filepath.Ext(filename) {
-	case contract.ExtYAML, contract.ExtYML, contract.ExtJSON:
-		return true
-	default:
-		return false
-	}
+	return decoder.Supports(filename)
 }
 
 // --- Type conversion helpers with overflow checks and static errors ---
@@ -232,6 +229,18 @@ func ToFloat32(val any) (float32, error) {
 			return 0, fmt.Errorf("%w: float64 out of float32 range", errors.ErrNotFloat32)
 		}
 
+		return float32(value), nil
+	case int:
+		return float32(value), nil
+	case int32:
+		return float32(value), nil
+	case int64:
+		return float32(value), nil
+	case uint:
+		return float32(value), nil
+	case uint32:
+		return float32(value), nil
+	case uint64:
 		return float32(value), nil
 	case string:
 		floatVal, err := strconv.ParseFloat(value, 32)
@@ -255,6 +264,18 @@ func ToFloat64(val any) (float64, error) {
 		return value, nil
 	case float32:
 		return float64(value), nil
+	case int:
+		return float64(value), nil
+	case int32:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case uint:
+		return float64(value), nil
+	case uint32:
+		return float64(value), nil
+	case uint64:
+		return float64(value), nil
 	case string:
 		f, err := strconv.ParseFloat(value, 64)
 		if err != nil {
@@ -322,19 +343,35 @@ func ToMap(val any) (map[string]any, error) {
 }
 
 func ToTime(val any) (time.Time, error) {
-	if t, ok := val.(time.Time); ok {
+	switch value := val.(type) {
+	case time.Time:
+		return value, nil
+	case string:
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %w", errors.ErrNotTime, err)
+		}
+
 		return t, nil
+	default:
+		return time.Time{}, errors.ErrNotTime
 	}
-
-	return time.Time{}, errors.ErrNotTime
 }
 
 func ToDuration(val any) (time.Duration, error) {
-	if d, ok := val.(time.Duration); ok {
+	switch value := val.(type) {
+	case time.Duration:
+		return value, nil
+	case string:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", errors.ErrNotDuration, err)
+		}
+
 		return d, nil
+	default:
+		return 0, errors.ErrNotDuration
 	}
-
-	return 0, errors.ErrNotDuration
 }
 
 func ToBytes(val any) ([]byte, error) {
@@ -0,0 +1,89 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/decoder"
+)
+
+// configFiles lists the supported config files directly inside dir, the same files
+// file.Loader.LoadFromDirectory loads.
+func configFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading directory: %w", err)
+	}
+
+	var paths []string
+
+	for _, entry := range entries {
+		if entry.IsDir() || !decoder.Supports(entry.Name()) {
+			continue
+		}
+
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+
+	return paths, nil
+}
+
+// directoryListing lists the supported config files directly inside dir.
+func directoryListing(dir string) contract.DirectoryListing {
+	return func() ([]string, []string, error) {
+		files, err := configFiles(dir)
+
+		return files, []string{dir}, err
+	}
+}
+
+// readListing takes listing and makes its paths absolute, so they compare equal to the
+// paths in file system events.
+func readListing(listing contract.DirectoryListing) ([]string, []string, error) {
+	files, dirs, err := listing()
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing directory: %w", err)
+	}
+
+	if files, err = absAll(files); err != nil {
+		return nil, nil, err
+	}
+
+	if dirs, err = absAll(dirs); err != nil {
+		return nil, nil, err
+	}
+
+	return files, dirs, nil
+}
+
+// absAll makes every path absolute.
+func absAll(paths []string) ([]string, error) {
+	result := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("resolving path: %w", err)
+		}
+
+		result = append(result, absPath)
+	}
+
+	return result, nil
+}
+
+// resolveAll maps every path to the file it currently resolves to. Paths that cannot
+// be resolved, such as dangling symlinks, are left out.
+func resolveAll(paths []string) map[string]string {
+	resolved := make(map[string]string, len(paths))
+
+	for _, path := range paths {
+		if realPath, err := filepath.EvalSymlinks(path); err == nil {
+			resolved[path] = realPath
+		}
+	}
+
+	return resolved
+}
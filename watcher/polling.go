@@ -0,0 +1,428 @@
+package watcher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	errors2 "errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
+)
+
+// DefaultPollInterval is how often a PollingWatcher checks its files by default.
+const DefaultPollInterval = time.Second
+
+// PollingWatcher watches files by checking them at a fixed interval instead of relying on
+// file system notifications, for NFS, FUSE mounts and overlay setups where fsnotify
+// misses changes. Use it with config.WithWatcher.
+//
+// A file counts as changed when its size or modification time differs and its content
+// hash differs too, so touching a file does not trigger a reload. Callbacks follow the
+// same rules as Watcher: changes found in one poll are dispatched together, a removed
+// file is reported again once it reappears, and a symlink that now points elsewhere is a
+// change.
+type PollingWatcher struct {
+	interval  time.Duration
+	files     map[string]*polledFile
+	dirs      map[string]*polledDir
+	callbacks []func()
+	onError   []func(error)
+	done      chan struct{}
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	started   bool
+}
+
+// polledFile is a registered file and what it looked like at the last poll.
+type polledFile struct {
+	callback func()
+	state    fileState
+}
+
+// polledDir is a registered directory, the listing of its config files and their
+// state at the last poll.
+type polledDir struct {
+	callback func()
+	listing  contract.DirectoryListing
+	states   map[string]fileState
+}
+
+// fileState is what a poll compares between two checks of a file.
+type fileState struct {
+	exists   bool
+	realPath string
+	modTime  time.Time
+	size     int64
+	hash     []byte
+}
+
+// NewPollingWatcher creates a PollingWatcher that checks its files every interval.
+// A non-positive interval uses DefaultPollInterval.
+func NewPollingWatcher(interval time.Duration) *PollingWatcher {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	return &PollingWatcher{
+		interval:  interval,
+		files:     make(map[string]*polledFile),
+		dirs:      make(map[string]*polledDir),
+		callbacks: nil,
+		onError:   nil,
+		done:      make(chan struct{}),
+		wg:        sync.WaitGroup{},
+		mu:        sync.Mutex{},
+		started:   false,
+	}
+}
+
+// AddFile adds a file to the watcher and registers its callback, which may be nil.
+// The file must exist when it is added.
+func (w *PollingWatcher) AddFile(path string, callback func()) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, path, err)
+	}
+
+	state, err := readFileState(absPath, fileState{})
+	if err == nil && !state.exists {
+		err = fs.ErrNotExist
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, path, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.files[absPath] = &polledFile{callback: callback, state: state}
+	w.startLocked()
+
+	return nil
+}
+
+// AddDirectory watches every supported config file in dir and registers a callback,
+// which may be nil, that runs when such a file is added, removed or changed.
+func (w *PollingWatcher) AddDirectory(dir string, callback func()) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, dir, err)
+	}
+
+	return w.AddListing(absDir, directoryListing(absDir), callback)
+}
+
+// AddListing watches the files listing returns for dir, like AddDirectory. The listing
+// is taken again on every poll, so files added to a subdirectory of a recursively
+// loaded dir are noticed, and changes to files it leaves out are ignored.
+func (w *PollingWatcher) AddListing(dir string, listing contract.DirectoryListing, callback func()) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, dir, err)
+	}
+
+	states, err := readDirState(listing, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, dir, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.dirs[absDir] = &polledDir{callback: callback, listing: listing, states: states}
+	w.startLocked()
+
+	return nil
+}
+
+// Watch registers a callback that runs once per poll that found changes, however many
+// files changed, and starts polling if not already running.
+func (w *PollingWatcher) Watch(callback func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callbacks = append(w.callbacks, callback)
+	w.startLocked()
+}
+
+// OnError registers a handler for files that could not be checked. The errors wrap
+// errors.ErrWatchFailed.
+func (w *PollingWatcher) OnError(handler func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.onError = append(w.onError, handler)
+}
+
+// Close stops polling and forgets every file and callback.
+func (w *PollingWatcher) Close() error {
+	w.mu.Lock()
+
+	if !w.started {
+		w.mu.Unlock()
+
+		return nil
+	}
+
+	close(w.done)
+	w.done = make(chan struct{})
+	w.files = make(map[string]*polledFile)
+	w.dirs = make(map[string]*polledDir)
+	w.callbacks = nil
+	w.onError = nil
+	w.started = false
+	w.mu.Unlock()
+
+	w.wg.Wait()
+
+	return nil
+}
+
+// startLocked starts the polling goroutine if not already started.
+// Assumes the caller holds w.mu.
+func (w *PollingWatcher) startLocked() {
+	if w.started {
+		return
+	}
+
+	w.started = true
+	w.wg.Add(1)
+
+	go w.run(w.done)
+}
+
+// run polls the files every interval until done is closed.
+func (w *PollingWatcher) run(done <-chan struct{}) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// poll checks every file and directory once and dispatches the callbacks of those that
+// changed.
+func (w *PollingWatcher) poll() {
+	w.mu.Lock()
+	files := make(map[string]fileState, len(w.files))
+
+	for path, file := range w.files {
+		files[path] = file.state
+	}
+
+	dirs := make(map[string]map[string]fileState, len(w.dirs))
+	listings := make(map[string]contract.DirectoryListing, len(w.dirs))
+
+	for path, dir := range w.dirs {
+		dirs[path], listings[path] = dir.states, dir.listing
+	}
+	w.mu.Unlock()
+
+	var failures []error
+
+	for path, before := range files {
+		state, err := readFileState(path, before)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%w: %s: %w", errors.ErrWatchFailed, path, err))
+
+			delete(files, path)
+
+			continue
+		}
+
+		files[path] = state
+	}
+
+	for path, before := range dirs {
+		states, err := readDirState(listings[path], before)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%w: %s: %w", errors.ErrWatchFailed, path, err))
+
+			delete(dirs, path)
+
+			continue
+		}
+
+		dirs[path] = states
+	}
+
+	callbacks, handlers := w.apply(files, dirs)
+
+	for _, err := range failures {
+		for _, handler := range handlers {
+			handler(err)
+		}
+	}
+
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+// apply stores the states found by a poll and returns the callbacks to run and the
+// error handlers to report to.
+func (w *PollingWatcher) apply(files map[string]fileState, dirs map[string]map[string]fileState) ([]func(), []func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var (
+		callbacks []func()
+		changed   bool
+	)
+
+	for path, state := range files {
+		file, ok := w.files[path]
+		if !ok {
+			continue // removed by Close while polling
+		}
+
+		fileChanged := state.changedFrom(file.state)
+		file.state = state
+
+		if fileChanged {
+			changed = true
+
+			if file.callback != nil {
+				callbacks = append(callbacks, file.callback)
+			}
+		}
+	}
+
+	for path, states := range dirs {
+		dir, ok := w.dirs[path]
+		if !ok {
+			continue
+		}
+
+		dirChanged := dirStateChanged(states, dir.states)
+		dir.states = states
+
+		if dirChanged {
+			changed = true
+
+			if dir.callback != nil {
+				callbacks = append(callbacks, dir.callback)
+			}
+		}
+	}
+
+	if changed {
+		callbacks = append(callbacks, w.callbacks...)
+	}
+
+	return callbacks, slices.Clone(w.onError)
+}
+
+// changedFrom reports whether a file should be reported as changed since before.
+func (s fileState) changedFrom(before fileState) bool {
+	switch {
+	case !s.exists:
+		return false // gone for now; reported once it reappears
+	case !before.exists, s.realPath != before.realPath:
+		return true
+	default:
+		return !bytes.Equal(s.hash, before.hash)
+	}
+}
+
+// readFileState checks path, following symlinks. The content is only hashed again when
+// the size or modification time differ from before.
+func readFileState(path string, before fileState) (fileState, error) {
+	realPath, err := filepath.EvalSymlinks(path)
+	if errors2.Is(err, fs.ErrNotExist) {
+		return fileState{exists: false, realPath: "", modTime: time.Time{}, size: 0, hash: nil}, nil
+	}
+
+	if err != nil {
+		return fileState{}, fmt.Errorf("resolving file: %w", err)
+	}
+
+	info, err := os.Stat(realPath)
+	if err != nil {
+		return fileState{}, fmt.Errorf("checking file: %w", err)
+	}
+
+	state := fileState{
+		exists:   true,
+		realPath: realPath,
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+		hash:     before.hash,
+	}
+
+	if before.exists && before.realPath == realPath && before.size == state.size && before.modTime.Equal(state.modTime) {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(realPath) // #nosec G304 -- path was registered by the caller
+	if err != nil {
+		return fileState{}, fmt.Errorf("reading file: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	state.hash = sum[:]
+
+	return state, nil
+}
+
+// readDirState checks every config file listing returns.
+func readDirState(listing contract.DirectoryListing, before map[string]fileState) (map[string]fileState, error) {
+	paths, _, err := readListing(listing)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]fileState, len(paths))
+
+	for _, path := range paths {
+		state, err := readFileState(path, before[path])
+		if err != nil {
+			return nil, err
+		}
+
+		if state.exists {
+			states[path] = state
+		}
+	}
+
+	return states, nil
+}
+
+// dirStateChanged reports whether a file was added to, removed from or changed in a
+// directory.
+func dirStateChanged(current, before map[string]fileState) bool {
+	if len(current) != len(before) {
+		return true
+	}
+
+	for path, state := range current {
+		if state.changedFrom(before[path]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Compile time checks for interfaces.
+var (
+	_ contract.Watcher          = (*PollingWatcher)(nil)
+	_ contract.DirectoryWatcher = (*PollingWatcher)(nil)
+	_ contract.ListingWatcher   = (*PollingWatcher)(nil)
+)
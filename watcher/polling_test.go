@@ -0,0 +1,143 @@
+package watcher_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/watcher"
+)
+
+const pollInterval = 20 * time.Millisecond
+
+// writeAtomic replaces path in one step, so that a poll never sees it half-written.
+func writeAtomic(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, data, 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func TestPollingWatcher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DetectsContentChanges", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		first := filepath.Join(dir, "a.yaml")
+		second := filepath.Join(dir, "b.yaml")
+		require.NoError(t, os.WriteFile(first, []byte("v: 1"), 0o600))
+		require.NoError(t, os.WriteFile(second, []byte("v: 1"), 0o600))
+
+		w := watcher.NewPollingWatcher(pollInterval)
+		t.Cleanup(func() { _ = w.Close() })
+
+		var fileCalls, batches atomic.Int32
+
+		require.NoError(t, w.AddFile(first, func() { fileCalls.Add(1) }))
+		require.NoError(t, w.AddFile(second, nil))
+		w.Watch(func() { batches.Add(1) })
+
+		// Touching a file without changing it is not a change.
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(first, later, later))
+		time.Sleep(5 * pollInterval)
+		assert.Zero(t, batches.Load())
+
+		writeAtomic(t, first, []byte("v: 22"))
+		require.Eventually(t, func() bool { return fileCalls.Load() == 1 }, time.Second, pollInterval)
+		require.Eventually(t, func() bool { return batches.Load() == 1 }, time.Second, pollInterval)
+
+		writeAtomic(t, second, []byte("v: 22"))
+		require.Eventually(t, func() bool { return batches.Load() == 2 }, time.Second, pollInterval)
+		assert.Equal(t, int32(1), fileCalls.Load())
+	})
+
+	t.Run("RemoveAndRecreate", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		configFile := filepath.Join(dir, "app.yaml")
+		require.NoError(t, os.WriteFile(configFile, []byte("v: 1"), 0o600))
+
+		w := watcher.NewPollingWatcher(pollInterval)
+		t.Cleanup(func() { _ = w.Close() })
+
+		var calls atomic.Int32
+
+		require.NoError(t, w.AddFile(configFile, func() { calls.Add(1) }))
+
+		require.NoError(t, os.Remove(configFile))
+		time.Sleep(5 * pollInterval)
+		assert.Zero(t, calls.Load(), "callback must not run while the file is missing")
+
+		writeAtomic(t, configFile, []byte("v: 1"))
+		require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, pollInterval)
+	})
+
+	t.Run("AddDirectory", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("v: 1"), 0o600))
+
+		w := watcher.NewPollingWatcher(pollInterval)
+		t.Cleanup(func() { _ = w.Close() })
+
+		var calls atomic.Int32
+
+		require.NoError(t, w.AddDirectory(dir, func() { calls.Add(1) }))
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not config"), 0o600))
+		time.Sleep(5 * pollInterval)
+		assert.Zero(t, calls.Load(), "unsupported files must not trigger the callback")
+
+		writeAtomic(t, filepath.Join(dir, "cache.yaml"), []byte("ttl: 60"))
+		require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, pollInterval)
+
+		writeAtomic(t, filepath.Join(dir, "app.yaml"), []byte("v: 22"))
+		require.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, pollInterval)
+
+		require.NoError(t, os.Remove(filepath.Join(dir, "cache.yaml")))
+		require.Eventually(t, func() bool { return calls.Load() == 3 }, time.Second, pollInterval)
+	})
+
+	t.Run("WatchNonExistentFile", func(t *testing.T) {
+		t.Parallel()
+
+		w := watcher.NewPollingWatcher(pollInterval)
+		require.ErrorIs(t, w.AddFile("/non/existent/file.yaml", nil), errors.ErrWatchSetupFailed)
+		require.NoError(t, w.Close())
+	})
+
+	t.Run("WithConfig", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		configFile := filepath.Join(dir, "app.yaml")
+		require.NoError(t, os.WriteFile(configFile, []byte("app:\n  name: before\n"), 0o600))
+
+		cfg := config.New(config.WithWatcher(watcher.NewPollingWatcher(pollInterval)))
+		t.Cleanup(func() { _ = cfg.Close() })
+
+		require.NoError(t, cfg.FileLoader().LoadFromFile(configFile))
+		require.NoError(t, cfg.Reload())
+		require.NoError(t, cfg.StartWatching(configFile))
+
+		require.NoError(t, os.WriteFile(configFile, []byte("app:\n  name: after\n"), 0o600))
+		require.Eventually(t, func() bool {
+			name, err := cfg.Lookup("app.name")
+
+			return err == nil && name == "after"
+		}, time.Second, pollInterval)
+	})
+}
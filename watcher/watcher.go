@@ -2,153 +2,490 @@ package watcher
 
 import (
 	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 
 	"github.com/hbttundar/scg-config/contract"
+	"github.com/hbttundar/scg-config/errors"
 )
 
 // Watcher provides file watching capabilities for configuration files.
+//
+// Files are watched through their parent directory rather than directly, so that a file
+// replaced by an atomic save (write to a temp file, then rename) or removed and created
+// again keeps being watched, and a Kubernetes ConfigMap update, which swaps the "..data"
+// symlink the mounted file points through, is noticed.
+//
+// A single save usually produces several events, so changes are collected until no new
+// event has arrived for the debounce window (or the max wait has passed since the first
+// one) and then dispatched together: each changed file's callback runs once, followed by
+// the callbacks registered with Watch.
 type Watcher struct {
-	config   contract.Config
-	watcher  *fsnotify.Watcher
-	done     chan struct{}
-	mu       sync.Mutex
-	eventMux sync.Mutex
-	wg       sync.WaitGroup
-	files    map[string]func()
-	started  bool
+	config    contract.Config
+	watcher   *fsnotify.Watcher
+	done      chan struct{}
+	mu        sync.Mutex
+	wg        sync.WaitGroup
+	files     map[string]*watchedFile
+	watchDirs map[string]*watchedDir
+	dirs      map[string]int
+	callbacks []func()
+	onError   []func(error)
+	debounce  time.Duration
+	maxWait   time.Duration
+	started   bool
+}
+
+const (
+	// DefaultDebounce is how long the watcher waits for more events before dispatching.
+	DefaultDebounce = 100 * time.Millisecond
+	// DefaultMaxWait bounds how long continuous events can delay a dispatch.
+	DefaultMaxWait = time.Second
+)
+
+// Option is a functional option for configuring the Watcher instance.
+type Option func(*Watcher)
+
+// WithDebounce sets how long the watcher waits after an event for more events before
+// dispatching them together. Zero dispatches every event as soon as it arrives.
+func WithDebounce(d time.Duration) Option { return func(w *Watcher) { w.debounce = d } }
+
+// WithMaxWait bounds how long a stream of events can keep postponing a dispatch.
+// Zero means no bound.
+func WithMaxWait(d time.Duration) Option { return func(w *Watcher) { w.maxWait = d } }
+
+// watchedFile is a registered file and the file it resolved to when last seen.
+type watchedFile struct {
+	callback func()
+	realPath string
+}
+
+// watchedDir is a registered directory, the listing of its config files, what they
+// resolved to when last seen and the directories subscribed to for it.
+type watchedDir struct {
+	callback func()
+	listing  contract.DirectoryListing
+	entries  map[string]string
+	dirs     []string
 }
 
 // NewWatcher creates a new Watcher instance.
-func NewWatcher(config contract.Config) *Watcher {
-	return &Watcher{
-		config:   config,
-		done:     make(chan struct{}),
-		files:    make(map[string]func()),
-		watcher:  nil,
-		started:  false,
-		mu:       sync.Mutex{},
-		eventMux: sync.Mutex{},
-		wg:       sync.WaitGroup{},
+func NewWatcher(config contract.Config, opts ...Option) *Watcher {
+	w := &Watcher{
+		config:    config,
+		done:      make(chan struct{}),
+		files:     make(map[string]*watchedFile),
+		watchDirs: make(map[string]*watchedDir),
+		dirs:      make(map[string]int),
+		callbacks: nil,
+		onError:   nil,
+		debounce:  DefaultDebounce,
+		maxWait:   DefaultMaxWait,
+		watcher:   nil,
+		started:   false,
+		mu:        sync.Mutex{},
+		wg:        sync.WaitGroup{},
+	}
+	for _, opt := range opts {
+		opt(w)
 	}
+
+	return w
 }
 
-// AddFile adds a file to the watcher and registers its callback.
+// AddFile adds a file to the watcher and registers its callback, which may be nil. The
+// file must exist when it is added; afterwards it may be replaced, renamed or removed and
+// recreated.
 func (w *Watcher) AddFile(path string, callback func()) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, path, err)
+	}
+
+	realPath, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, path, err)
+	}
+
 	if w.watcher == nil {
 		newWatcher, err := fsnotify.NewWatcher()
 		if err != nil {
-			return fmt.Errorf("failed to create file watcher: %w", err)
+			return fmt.Errorf("%w: %w", errors.ErrWatchSetupFailed, err)
 		}
 
 		w.watcher = newWatcher
 	}
 
-	if err := w.watcher.Add(path); err != nil {
-		return fmt.Errorf("failed to add file to watcher: %w", err)
+	if _, ok := w.files[absPath]; !ok {
+		if err := w.addDirLocked(filepath.Dir(absPath)); err != nil {
+			return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, path, err)
+		}
 	}
 
-	w.files[path] = callback
+	w.files[absPath] = &watchedFile{callback: callback, realPath: realPath}
 	w.startLocked()
 
 	return nil
 }
 
-// Watch starts the watcher loop if not already running.
-func (w *Watcher) Watch(callback func()) {
+// AddDirectory watches every supported config file in dir and registers a callback,
+// which may be nil, that runs when such a file is added, removed or changed, or when
+// a symlink swap makes the files resolve elsewhere.
+func (w *Watcher) AddDirectory(dir string, callback func()) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, dir, err)
+	}
+
+	return w.AddListing(absDir, directoryListing(absDir), callback)
+}
+
+// AddListing watches the files listing returns for dir, like AddDirectory, and every
+// directory it returns, so files added to a subdirectory of a recursively loaded dir are
+// noticed. Changes to files listing leaves out, such as excluded ones, are ignored.
+func (w *Watcher) AddListing(dir string, listing contract.DirectoryListing, callback func()) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	for path := range w.files {
-		w.files[path] = callback
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, dir, err)
+	}
+
+	paths, dirs, err := readListing(listing)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, dir, err)
+	}
+
+	if w.watcher == nil {
+		newWatcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("%w: %w", errors.ErrWatchSetupFailed, err)
+		}
+
+		w.watcher = newWatcher
+	}
+
+	watched := &watchedDir{callback: callback, listing: listing, entries: resolveAll(paths), dirs: nil}
+	if err := w.subscribeLocked(watched, dirs); err != nil {
+		for _, subscribed := range watched.dirs {
+			w.releaseDirLocked(subscribed)
+		}
+
+		return fmt.Errorf("%w: %s: %w", errors.ErrWatchSetupFailed, dir, err)
 	}
 
+	if previous, ok := w.watchDirs[absDir]; ok {
+		for _, dir := range previous.dirs {
+			w.releaseDirLocked(dir)
+		}
+	}
+
+	w.watchDirs[absDir] = watched
+	w.startLocked()
+
+	return nil
+}
+
+// subscribeLocked makes watched subscribe to exactly dirs, adding the new ones and
+// releasing those it no longer needs. Assumes the caller holds w.mu.
+func (w *Watcher) subscribeLocked(watched *watchedDir, dirs []string) error {
+	for _, dir := range dirs {
+		if slices.Contains(watched.dirs, dir) {
+			continue
+		}
+
+		if err := w.addDirLocked(dir); err != nil {
+			return err
+		}
+
+		watched.dirs = append(watched.dirs, dir)
+	}
+
+	kept := watched.dirs[:0]
+
+	for _, dir := range watched.dirs {
+		if slices.Contains(dirs, dir) {
+			kept = append(kept, dir)
+		} else {
+			w.releaseDirLocked(dir)
+		}
+	}
+
+	watched.dirs = kept
+
+	return nil
+}
+
+// addDirLocked subscribes to events in dir unless something already did.
+// Assumes the caller holds w.mu.
+func (w *Watcher) addDirLocked(dir string) error {
+	if w.dirs[dir] == 0 {
+		if err := w.watcher.Add(dir); err != nil {
+			return fmt.Errorf("adding directory: %w", err)
+		}
+	}
+
+	w.dirs[dir]++
+
+	return nil
+}
+
+// releaseDirLocked undoes one addDirLocked, unsubscribing from dir once nothing needs
+// it. Assumes the caller holds w.mu.
+func (w *Watcher) releaseDirLocked(dir string) {
+	if w.dirs[dir]--; w.dirs[dir] > 0 {
+		return
+	}
+
+	delete(w.dirs, dir)
+	// A removed directory has already been dropped by fsnotify
+	_ = w.watcher.Remove(dir)
+}
+
+// Watch registers a callback that runs once per dispatch, however many watched files
+// changed, and starts the watcher loop if not already running.
+func (w *Watcher) Watch(callback func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callbacks = append(w.callbacks, callback)
 	w.startLocked()
 }
 
+// OnError registers a handler for errors reported by the file system while watching,
+// such as an event queue overflow. They wrap errors.ErrWatchFailed.
+func (w *Watcher) OnError(handler func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.onError = append(w.onError, handler)
+}
+
 // startLocked starts the watcher goroutine if not already started.
 // Assumes the caller holds w.mu.
 func (w *Watcher) startLocked() {
-	if w.started {
+	if w.started || w.watcher == nil {
 		return
 	}
 
 	w.started = true
 	w.wg.Add(1)
 
-	go w.run()
+	go w.run(w.watcher, w.done)
 }
 
-// run is the goroutine that dispatches file system events.
-func (w *Watcher) run() {
+// run is the goroutine that collects file system events and dispatches them.
+func (w *Watcher) run(fsWatcher *fsnotify.Watcher, done <-chan struct{}) {
 	defer w.wg.Done()
 
+	var (
+		pending = make(map[string]bool)
+		first   time.Time
+		timer   *time.Timer
+		flushC  <-chan time.Time
+	)
+
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
 	for {
 		select {
-		case <-w.done:
+		case <-done:
 			return
-		case event, ok := <-w.watcher.Events:
+		case event, ok := <-fsWatcher.Events:
 			if !ok {
 				return
 			}
 
-			w.handleEvent(event)
-		case err, ok := <-w.watcher.Errors:
+			changed := w.affected(event)
+			if len(changed) == 0 {
+				continue
+			}
+
+			for _, path := range changed {
+				pending[path] = true
+			}
+
+			now := time.Now()
+			if first.IsZero() {
+				first = now
+			}
+
+			wait := w.delay(now.Sub(first))
+			if wait <= 0 {
+				w.dispatch(pending)
+				pending, first, flushC = make(map[string]bool), time.Time{}, nil
+
+				continue
+			}
+
+			if timer == nil {
+				timer = time.NewTimer(wait)
+			} else {
+				timer.Reset(wait)
+			}
+
+			flushC = timer.C
+		case <-flushC:
+			w.dispatch(pending)
+			pending, first, flushC = make(map[string]bool), time.Time{}, nil
+		case err, ok := <-fsWatcher.Errors:
 			// Check if the error channel has been closed
 			if !ok {
 				return
 			}
-			// Optionally log the error or handle it here. We assign to the blank identifier
-			// to avoid unused variable warnings without leaving a dangling comment as the
-			// last statement in the block.
-			_ = err
+
+			w.reportError(fmt.Errorf("%w: %w", errors.ErrWatchFailed, err))
 		}
 	}
 }
 
-// handleEvent is called for every fsnotify event.
-func (w *Watcher) handleEvent(event fsnotify.Event) {
-	w.eventMux.Lock()
-	defer w.eventMux.Unlock()
+// reportError passes err to every OnError handler.
+func (w *Watcher) reportError(err error) {
+	w.mu.Lock()
+	handlers := slices.Clone(w.onError)
+	w.mu.Unlock()
 
-	if event.Op&fsnotify.Write == fsnotify.Write {
-		if reloadable, ok := w.config.(interface{ ReloadConfig() }); ok {
-			reloadable.ReloadConfig()
-		}
+	for _, handler := range handlers {
+		handler(err)
+	}
+}
 
-		w.mu.Lock()
-		cb := w.files[event.Name]
-		w.mu.Unlock()
+// delay returns how long to wait for more events when the current batch started
+// elapsed ago.
+func (w *Watcher) delay(elapsed time.Duration) time.Duration {
+	wait := w.debounce
+	if w.maxWait > 0 && w.maxWait-elapsed < wait {
+		wait = w.maxWait - elapsed
+	}
+
+	return wait
+}
+
+// dispatch runs the callback of every changed file once, then every Watch callback.
+func (w *Watcher) dispatch(changed map[string]bool) {
+	w.mu.Lock()
 
-		if cb != nil {
-			cb()
+	callbacks := make([]func(), 0, len(changed)+len(w.callbacks))
+
+	for path := range changed {
+		if file, ok := w.files[path]; ok && file.callback != nil {
+			callbacks = append(callbacks, file.callback)
+		}
+
+		if dir, ok := w.watchDirs[path]; ok && dir.callback != nil {
+			callbacks = append(callbacks, dir.callback)
 		}
 	}
+
+	callbacks = append(callbacks, w.callbacks...)
+	w.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback()
+	}
 }
 
-// Close stops the watcher.
-func (w *Watcher) Close() error {
+// affected returns the watched files and directories that event changed.
+//
+// An event on the file itself counts unless the file is gone (removed, or renamed away
+// as the first half of an atomic save): it counts again once the file reappears. Any
+// other event in the directory counts for a file whose symlink now resolves to a
+// different target, which is how a ConfigMap "..data" swap shows up. A watched
+// directory changes when one of its listed files is touched or its listing changes.
+func (w *Watcher) affected(event fsnotify.Event) []string {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if w.watcher != nil {
-		close(w.done)
-		w.wg.Wait()
-		err := w.watcher.Close()
-		w.watcher = nil
-		w.files = make(map[string]func())
-		w.started = false
+	var changed []string
+
+	dir := filepath.Dir(event.Name)
+
+	for path, file := range w.files {
+		if filepath.Dir(path) != dir {
+			continue
+		}
+
+		realPath, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			continue // gone for now; a Create event will bring it back
+		}
+
+		retargeted := realPath != file.realPath
+		file.realPath = realPath
 
+		if path == event.Name || retargeted {
+			changed = append(changed, path)
+		}
+	}
+
+	for root, watched := range w.watchDirs {
+		if !slices.Contains(watched.dirs, dir) && !slices.Contains(watched.dirs, event.Name) {
+			continue
+		}
+
+		paths, dirs, err := readListing(watched.listing)
 		if err != nil {
-			return fmt.Errorf("error closing fsnotify watcher: %w", err)
+			continue
+		}
+
+		entries := resolveAll(paths)
+		_, listed := entries[event.Name]
+		listingChanged := !maps.Equal(entries, watched.entries)
+		watched.entries = entries
+
+		// A directory that cannot be subscribed to yet is tried again on the next event
+		_ = w.subscribeLocked(watched, dirs)
+
+		if listingChanged || listed {
+			changed = append(changed, root)
 		}
 	}
 
+	return changed
+}
+
+// Close stops the watcher.
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+
+	fsWatcher := w.watcher
+	if fsWatcher == nil {
+		w.mu.Unlock()
+
+		return nil
+	}
+
+	close(w.done)
+	w.done = make(chan struct{})
+	w.watcher = nil
+	w.files = make(map[string]*watchedFile)
+	w.watchDirs = make(map[string]*watchedDir)
+	w.dirs = make(map[string]int)
+	w.callbacks = nil
+	w.onError = nil
+	w.started = false
+	w.mu.Unlock()
+
+	// Wait without holding w.mu: the event loop may need it to finish the current event.
+	w.wg.Wait()
+
+	if err := fsWatcher.Close(); err != nil {
+		return fmt.Errorf("error closing fsnotify watcher: %w", err)
+	}
+
 	return nil
 }
 
@@ -166,5 +503,9 @@ func (w *Watcher) GetConfig() contract.Config {
 	return w.config
 }
 
-// Compile time checks for interface.
-var _ contract.Watcher = (*Watcher)(nil)
+// Compile time checks for interfaces.
+var (
+	_ contract.Watcher          = (*Watcher)(nil)
+	_ contract.DirectoryWatcher = (*Watcher)(nil)
+	_ contract.ListingWatcher   = (*Watcher)(nil)
+)
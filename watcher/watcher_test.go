@@ -1,16 +1,56 @@
 package watcher_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/hbttundar/scg-config/config"
+	"github.com/hbttundar/scg-config/errors"
+	"github.com/hbttundar/scg-config/watcher"
 )
 
+// watchCalls adds path to a new watcher and returns a channel that receives one value per
+// callback invocation.
+func watchCalls(t *testing.T, path string) <-chan struct{} {
+	t.Helper()
+
+	w := watcher.NewWatcher(nil)
+	t.Cleanup(func() { _ = w.Close() })
+
+	calls := make(chan struct{}, 16)
+
+	require.NoError(t, w.AddFile(path, func() { calls <- struct{}{} }))
+
+	return calls
+}
+
+func requireCalled(t *testing.T, calls <-chan struct{}, msg string) {
+	t.Helper()
+
+	select {
+	case <-calls:
+	case <-time.After(2 * time.Second):
+		t.Fatal(msg)
+	}
+}
+
+func drain(calls <-chan struct{}) {
+	for {
+		select {
+		case <-calls:
+		case <-time.After(100 * time.Millisecond):
+			return
+		}
+	}
+}
+
 func TestWatcher(t *testing.T) {
 	t.Parallel()
 
@@ -66,6 +106,160 @@ func TestWatcher(t *testing.T) {
 		defer func() { _ = watcher.Close() }()
 
 		err := watcher.AddFile("/non/existent/file.yaml", func() {})
-		require.Error(t, err)
+		require.ErrorIs(t, err, errors.ErrWatchSetupFailed)
+	})
+	t.Run("AtomicRenameSave", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		configFile := filepath.Join(dir, "app.yaml")
+		require.NoError(t, os.WriteFile(configFile, []byte("v: 1"), 0o600))
+
+		calls := watchCalls(t, configFile)
+
+		// The file must stay watched after being replaced, so save twice.
+		for range 2 {
+			tmp := filepath.Join(dir, ".app.yaml.tmp")
+			require.NoError(t, os.WriteFile(tmp, []byte("v: 2"), 0o600))
+			require.NoError(t, os.Rename(tmp, configFile))
+			requireCalled(t, calls, "rename over the watched file was not noticed")
+			drain(calls)
+		}
+	})
+
+	t.Run("RemoveAndRecreate", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		configFile := filepath.Join(dir, "app.yaml")
+		require.NoError(t, os.WriteFile(configFile, []byte("v: 1"), 0o600))
+
+		calls := watchCalls(t, configFile)
+
+		require.NoError(t, os.Remove(configFile))
+
+		select {
+		case <-calls:
+			t.Fatal("callback must not run while the file is missing")
+		case <-time.After(200 * time.Millisecond):
+		}
+
+		require.NoError(t, os.WriteFile(configFile, []byte("v: 2"), 0o600))
+		requireCalled(t, calls, "recreated file was not noticed")
+	})
+
+	t.Run("SymlinkSwap", func(t *testing.T) {
+		t.Parallel()
+
+		// Mimic a Kubernetes ConfigMap volume:
+		// app.yaml -> ..data/app.yaml, ..data -> ..v1
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "..v1"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "..v1", "app.yaml"), []byte("v: 1"), 0o600))
+		require.NoError(t, os.Symlink("..v1", filepath.Join(dir, "..data")))
+		require.NoError(t, os.Symlink(filepath.Join("..data", "app.yaml"), filepath.Join(dir, "app.yaml")))
+
+		calls := watchCalls(t, filepath.Join(dir, "app.yaml"))
+
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "..v2"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "..v2", "app.yaml"), []byte("v: 2"), 0o600))
+		require.NoError(t, os.Symlink("..v2", filepath.Join(dir, "..data_tmp")))
+		require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+		requireCalled(t, calls, "symlink swap was not noticed")
 	})
 }
+
+func TestWatcher_Debounce(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CoalescesBurstAcrossFiles", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		first := filepath.Join(dir, "a.yaml")
+		second := filepath.Join(dir, "b.yaml")
+		require.NoError(t, os.WriteFile(first, []byte("v: 0"), 0o600))
+		require.NoError(t, os.WriteFile(second, []byte("v: 0"), 0o600))
+
+		w := watcher.NewWatcher(nil, watcher.WithDebounce(200*time.Millisecond))
+		t.Cleanup(func() { _ = w.Close() })
+
+		var fileCalls, batches atomic.Int32
+
+		require.NoError(t, w.AddFile(first, func() { fileCalls.Add(1) }))
+		require.NoError(t, w.AddFile(second, nil))
+		w.Watch(func() { batches.Add(1) })
+
+		for i := range 5 {
+			content := []byte(fmt.Sprintf("v: %d", i))
+			require.NoError(t, os.WriteFile(first, content, 0o600))
+			require.NoError(t, os.WriteFile(second, content, 0o600))
+		}
+
+		require.Eventually(t, func() bool { return batches.Load() == 1 }, 2*time.Second, 10*time.Millisecond)
+		time.Sleep(400 * time.Millisecond)
+		assert.Equal(t, int32(1), batches.Load(), "a burst of writes must be dispatched once")
+		assert.Equal(t, int32(1), fileCalls.Load())
+	})
+
+	t.Run("MaxWaitBoundsContinuousWrites", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		configFile := filepath.Join(dir, "app.yaml")
+		require.NoError(t, os.WriteFile(configFile, []byte("v: 0"), 0o600))
+
+		w := watcher.NewWatcher(nil, watcher.WithDebounce(300*time.Millisecond), watcher.WithMaxWait(200*time.Millisecond))
+		t.Cleanup(func() { _ = w.Close() })
+
+		var batches atomic.Int32
+
+		require.NoError(t, w.AddFile(configFile, nil))
+		w.Watch(func() { batches.Add(1) })
+
+		// Keep writing more often than the debounce window for well over the max wait.
+		deadline := time.Now().Add(time.Second)
+		for i := 0; time.Now().Before(deadline); i++ {
+			require.NoError(t, os.WriteFile(configFile, []byte(fmt.Sprintf("v: %d", i)), 0o600))
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		assert.GreaterOrEqual(t, batches.Load(), int32(2), "continuous writes must still be dispatched")
+	})
+}
+
+func TestWatcher_AddDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("v: 1"), 0o600))
+
+	w := watcher.NewWatcher(nil, watcher.WithDebounce(50*time.Millisecond))
+	t.Cleanup(func() { _ = w.Close() })
+
+	calls := make(chan struct{}, 16)
+
+	require.NoError(t, w.AddDirectory(dir, func() { calls <- struct{}{} }))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not config"), 0o600))
+
+	select {
+	case <-calls:
+		t.Fatal("unsupported files must not trigger the callback")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cache.yaml"), []byte("ttl: 60"), 0o600))
+	requireCalled(t, calls, "added file was not noticed")
+	drain(calls)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("v: 2"), 0o600))
+	requireCalled(t, calls, "changed file was not noticed")
+	drain(calls)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "cache.yaml")))
+	requireCalled(t, calls, "removed file was not noticed")
+
+	require.ErrorIs(t, w.AddDirectory(filepath.Join(dir, "missing"), nil), errors.ErrWatchSetupFailed)
+}